@@ -0,0 +1,254 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// AdaptiveConcurrency configures a concurrency limit which adapts to the gateway's capacity, in the style of TCP congestion control.
+// Whenever a call is rate limited, with connect.CodeResourceExhausted, the limit is halved; after each run of successful calls
+// as long as the current limit, it is raised by one. This self-tunes throughput without manual tuning.
+// Calls over the limit wait for an earlier call to complete, or for their context to be done.
+type AdaptiveConcurrency struct {
+	// MinConcurrency is the lowest the limit can go. If unspecified, it defaults to 1.
+	MinConcurrency int
+
+	// MaxConcurrency is the highest the limit can go, which must not be lower than MinConcurrency.
+	MaxConcurrency int
+
+	// InitialConcurrency is the limit when the client is created. If unspecified, it defaults to MaxConcurrency.
+	InitialConcurrency int
+}
+
+// adaptiveLimiter limits the number of calls in flight, adjusting the limit with additive increase and multiplicative decrease.
+type adaptiveLimiter struct {
+	mu sync.Mutex
+
+	min   int
+	max   int
+	limit int
+
+	inFlight int
+
+	// Successful calls since the limit last changed.
+	successes int
+
+	// Incremented whenever the limit is decreased, so that calls started before a decrease do not decrease it again.
+	generation int
+
+	// Calls waiting for a slot, in order. A slot is handed over to a waiter by closing its channel.
+	waiters []chan struct{}
+}
+
+// Validates the options and creates a limiter, or returns nil if options is nil.
+func newAdaptiveLimiter(options *AdaptiveConcurrency) (*adaptiveLimiter, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	minimum := options.MinConcurrency
+	if minimum <= 0 {
+		minimum = 1
+	}
+	if options.MaxConcurrency < minimum {
+		return nil, fmt.Errorf("maximum concurrency %d must be at least the minimum concurrency %d", options.MaxConcurrency, minimum)
+	}
+
+	limit := options.InitialConcurrency
+	if limit <= 0 {
+		limit = options.MaxConcurrency
+	}
+
+	return &adaptiveLimiter{
+		min:   minimum,
+		max:   options.MaxConcurrency,
+		limit: min(max(limit, minimum), options.MaxConcurrency),
+	}, nil
+}
+
+// Blocks until a slot is available, or ctx is done. It returns the generation the call started in, to pass to release.
+func (l *adaptiveLimiter) acquire(ctx context.Context) (int, error) {
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.inFlight++
+		generation := l.generation
+		l.mu.Unlock()
+		return generation, nil
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.generation, nil
+
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if i := slices.Index(l.waiters, ready); i >= 0 {
+			l.waiters = slices.Delete(l.waiters, i, i+1)
+		} else {
+			// The slot was handed over just as ctx was done, so pass it on.
+			l.inFlight--
+			l.wake()
+		}
+		return 0, ctx.Err()
+	}
+}
+
+// Frees the slot of a call which started in the given generation, and adjusts the limit according to its error.
+func (l *adaptiveLimiter) release(generation int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	switch {
+	case connect.CodeOf(err) == connect.CodeResourceExhausted:
+		if generation == l.generation {
+			l.limit = max(l.limit/2, l.min)
+			l.successes = 0
+			l.generation++
+		}
+	case err == nil || errors.Is(err, io.EOF):
+		l.successes++
+		if l.successes >= l.limit && l.limit < l.max {
+			l.limit++
+			l.successes = 0
+		}
+	}
+	l.wake()
+}
+
+// Hands over free slots to waiters, in order. The caller must hold l.mu.
+func (l *adaptiveLimiter) wake() {
+	for len(l.waiters) > 0 && l.inFlight < l.limit {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// Returns the current limit and the number of calls in flight.
+func (l *adaptiveLimiter) stats() (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.inFlight
+}
+
+// Concurrency returns the current limit of ClientOptions.AdaptiveConcurrency, and the number of calls in flight,
+// for observability. If adaptive concurrency is disabled, both values are 0.
+func (c *Client) Concurrency() (limit int, inFlight int) {
+	if c.adaptiveLimiter == nil {
+		return 0, 0
+	}
+	return c.adaptiveLimiter.stats()
+}
+
+// adaptiveConcurrencyInterceptor holds a slot of the limiter for the duration of each call.
+// Streams hold their slot from when the request is sent until they are closed.
+type adaptiveConcurrencyInterceptor struct {
+	limiter *adaptiveLimiter
+}
+
+func (i *adaptiveConcurrencyInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if i.limiter == nil {
+			return next(ctx, req)
+		}
+
+		generation, err := i.limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := next(ctx, req)
+		i.limiter.release(generation, err)
+		return res, err
+	}
+}
+
+func (i *adaptiveConcurrencyInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if i.limiter == nil {
+			return conn
+		}
+
+		return &adaptiveConcurrencyConn{
+			StreamingClientConn: conn,
+			ctx:                 ctx,
+			limiter:             i.limiter,
+		}
+	}
+}
+
+func (i *adaptiveConcurrencyInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// adaptiveConcurrencyConn acquires a slot before sending the request message of a stream, and releases it once the stream ends or is closed,
+// whichever comes first, so that streams read to their end without being closed do not keep their slot.
+type adaptiveConcurrencyConn struct {
+	connect.StreamingClientConn
+
+	ctx     context.Context
+	limiter *adaptiveLimiter
+
+	acquired   bool
+	generation int
+
+	// The error that ended the stream, reported to the limiter on release.
+	err error
+}
+
+func (c *adaptiveConcurrencyConn) Send(msg any) error {
+	if !c.acquired {
+		generation, err := c.limiter.acquire(c.ctx)
+		if err != nil {
+			return err
+		}
+		c.acquired = true
+		c.generation = generation
+	}
+
+	err := c.StreamingClientConn.Send(msg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.err = err
+	}
+	return err
+}
+
+func (c *adaptiveConcurrencyConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+		}
+		c.release()
+	}
+	return err
+}
+
+func (c *adaptiveConcurrencyConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+	c.release()
+	return err
+}
+
+// Releases the slot of the stream, once.
+func (c *adaptiveConcurrencyConn) release() {
+	if !c.acquired {
+		return
+	}
+	c.acquired = false
+	c.limiter.release(c.generation, c.err)
+}
@@ -0,0 +1,117 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"time"
+)
+
+// API is the set of inference methods offered by the Function Network.
+// It is implemented by *Client, and by the stubs in the sdktest package, so that code depending on it can be tested
+// or developed without network access. Implementations which do not make network calls may ignore call options.
+type API interface {
+	ChatComplete(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, opts ...CallOption) (*apigatewayv1.ChatCompleteResponse, error)
+	ChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...CallOption) (*ChatCompleteStreamResponse, error)
+	Embed(ctx context.Context, request *apigatewayv1.EmbedRequest, opts ...CallOption) (*apigatewayv1.EmbedResponse, error)
+	TextToImage(ctx context.Context, request *apigatewayv1.TextToImageRequest, opts ...CallOption) (*apigatewayv1.TextToImageResponse, error)
+	Transcribe(ctx context.Context, request *apigatewayv1.TranscribeRequest, opts ...CallOption) (*apigatewayv1.TranscribeResponse, error)
+}
+
+var _ API = (*Client)(nil)
+
+// FunctionClient is the full set of methods of *Client, including streaming and batch methods,
+// so that code depending on a client can be unit tested with a mock, such as mock.Client, without a gateway.
+// Code which only makes inference calls should prefer API, which is smaller and also implemented by the sdktest stubs.
+type FunctionClient interface {
+	API
+
+	Complete(ctx context.Context, request *ChatRequest, opts ...CallOption) (*ChatResponse, error)
+	CompleteStream(ctx context.Context, request *ChatRequest, opts ...CallOption) (*ChatCompleteStreamResponse, error)
+	CreateEmbedding(ctx context.Context, request *EmbedRequest, opts ...CallOption) (*EmbedResponse, error)
+	GenerateImage(ctx context.Context, request *ImageRequest, opts ...CallOption) (*ImageResponse, error)
+	TranscribeAudio(ctx context.Context, request *TranscribeRequest, opts ...CallOption) (*TranscribeResponse, error)
+
+	Chat(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, stream bool) (string, *ResponseStream[apigatewayv1.ChatCompleteStreamResponse, string], error)
+	ChatCompleteStreamPersist(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, w io.Writer) (string, error)
+	ChatCompleteStreamRaw(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest) (*connect.ServerStreamForClient[apigatewayv1.ChatCompleteStreamResponse], error)
+	ForwardChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, dst *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error
+	CompareModels(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, models []string) (map[string]string, error)
+
+	EmbedBatch(ctx context.Context, model string, inputs []string) ([][]float32, error)
+	EmbedBatchPartial(ctx context.Context, model string, inputs []string) (map[int][]float32, error)
+	BenchmarkEmbed(ctx context.Context, model string, sampleInputs []string, duration time.Duration) (*ThroughputReport, error)
+
+	TextToImageBatch(ctx context.Context, prompts []string, shared *apigatewayv1.TextToImageRequest, concurrency int) ([]*apigatewayv1.TextToImageResponse, error)
+	FetchImage(ctx context.Context, url string) (*http.Response, error)
+	SaveImage(ctx context.Context, url string, w io.Writer, options *SaveImageOptions) error
+
+	ContextWindow(ctx context.Context, model string) (maxInput int, maxOutput int, err error)
+	CheckRequestSize(ctx context.Context, request proto.Message) error
+	SmokeTest(ctx context.Context, model string, modality Modality, options *SmokeTestOptions) (*SmokeResult, error)
+
+	Concurrency() (limit int, inFlight int)
+	CancelAll()
+}
+
+var _ FunctionClient = (*Client)(nil)
+
+// StaticChatCompleteStream creates a stream response which yields the given tokens in order, without any network activity.
+// If err is not nil, it is returned by Read after the last token, as if the stream had failed at that point.
+// This is intended for stubs and tests of code that consumes streams.
+func StaticChatCompleteStream(role string, tokens []string, err error) *ChatCompleteStreamResponse {
+	chunks := make([]*apigatewayv1.ChatCompleteStreamResponse, len(tokens))
+	for i, token := range tokens {
+		chunks[i] = &apigatewayv1.ChatCompleteStreamResponse{
+			Response: &apigatewayv1.ChatCompleteMessage{Role: role, Content: token},
+		}
+	}
+
+	return &ChatCompleteStreamResponse{
+		Role:        role,
+		TokenStream: wrapStream("ChatCompleteStream", &staticChunkReceiver[apigatewayv1.ChatCompleteStreamResponse]{chunks: chunks, err: err}, chatCompleteStreamToStringTransformer),
+	}
+}
+
+// staticChunkReceiver is a chunk source which yields chunks from a slice, and then an optional error.
+type staticChunkReceiver[T any] struct {
+	chunks  []*T
+	err     error
+	current *T
+
+	// Whether all chunks were received, in which case err is reported.
+	exhausted bool
+	closed    bool
+}
+
+func (s *staticChunkReceiver[T]) Receive() bool {
+	if s.closed || s.exhausted {
+		return false
+	}
+	if len(s.chunks) == 0 {
+		s.exhausted = true
+		return false
+	}
+
+	s.current, s.chunks = s.chunks[0], s.chunks[1:]
+	return true
+}
+
+func (s *staticChunkReceiver[T]) Msg() *T {
+	return s.current
+}
+
+func (s *staticChunkReceiver[T]) Err() error {
+	if s.exhausted {
+		return s.err
+	}
+	return nil
+}
+
+func (s *staticChunkReceiver[T]) Close() error {
+	s.closed = true
+	return nil
+}
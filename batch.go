@@ -0,0 +1,158 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"slices"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the default maximum number of concurrent requests made by batch helpers.
+const DefaultBatchConcurrency = 4
+
+// BatchError is returned by batch helpers when one or more items in the batch failed.
+// Items that succeeded are still returned alongside the error.
+type BatchError struct {
+	// Errors holds the error for each item in the batch, in the same order as the batch inputs.
+	// Items that succeeded have a nil error.
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e.Errors {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+
+	return fmt.Sprintf("%d of %d batch items failed, first error: %v", failed, len(e.Errors), first)
+}
+
+// Unwrap returns the errors of the failed items, so that errors.Is and errors.As match any of them.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Runs fn for each index in [0, n), with at most concurrency calls running at once.
+// If any call fails, a *BatchError holding every call's error is returned.
+func runBatch(n int, concurrency int, fn func(i int) error) error {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	errs := make([]error, n)
+	failed := false
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := fn(i); err != nil {
+				mu.Lock()
+				errs[i] = err
+				failed = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed {
+		return &BatchError{Errors: errs}
+	}
+	return nil
+}
+
+// DuplicateModelError is returned by CompareModels when a model is given more than once, as responses are keyed by model name.
+type DuplicateModelError struct {
+	// Model is the first model that was given more than once.
+	Model string
+}
+
+func (e *DuplicateModelError) Error() string {
+	return fmt.Sprintf("model %q is given more than once", e.Model)
+}
+
+// CompareModels sends the same chat request to each of the given models concurrently, and returns each model's full response content keyed by model name.
+// The model specified in the request itself is ignored. At most DefaultBatchConcurrency requests are made at once.
+// This is intended for model evaluation and A/B comparisons.
+//
+// Each model may only be given once, or a *DuplicateModelError is returned without making any request.
+// If any model fails, a *BatchError is returned whose errors are in the same order as models,
+// alongside the responses of the models that succeeded.
+func (c *Client) CompareModels(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, models []string) (map[string]string, error) {
+	if request == nil {
+		return nil, c.methodError("CompareModels", NilRequestError)
+	}
+	for i, model := range models {
+		if slices.Contains(models[:i], model) {
+			return nil, c.methodError("CompareModels", &DuplicateModelError{Model: model})
+		}
+	}
+
+	responses := make(map[string]string, len(models))
+	var mu sync.Mutex
+
+	err := runBatch(len(models), DefaultBatchConcurrency, func(i int) error {
+		res, err := c.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{
+			Model:   models[i],
+			Message: request.Message,
+		})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		responses[models[i]] = res.Response.GetContent()
+		mu.Unlock()
+		return nil
+	})
+
+	return responses, c.methodError("CompareModels", err)
+}
+
+// TextToImageBatch generates images for each of the prompts concurrently, with at most concurrency requests at once,
+// such as for creating a set of product variations. Each request is a copy of shared with its prompt replaced,
+// so that the model, size, quality, and count are shared by every prompt. If concurrency is zero or negative, DefaultBatchConcurrency is used.
+//
+// The returned responses are in the same order as the prompts.
+// If any prompt fails, a *BatchError is returned whose errors are in the same order as prompts,
+// alongside the responses, which are nil for the prompts that failed.
+//
+// Please refer to the developer docs to find a suitable model to use.
+func (c *Client) TextToImageBatch(ctx context.Context, prompts []string, shared *apigatewayv1.TextToImageRequest, concurrency int) ([]*apigatewayv1.TextToImageResponse, error) {
+	if shared == nil {
+		return nil, c.methodError("TextToImageBatch", NilRequestError)
+	}
+
+	responses := make([]*apigatewayv1.TextToImageResponse, len(prompts))
+	err := runBatch(len(prompts), concurrency, func(i int) error {
+		request := proto.Clone(shared).(*apigatewayv1.TextToImageRequest)
+		request.Prompt = prompts[i]
+
+		res, err := c.TextToImage(ctx, request)
+		responses[i] = res
+		return err
+	})
+
+	return responses, c.methodError("TextToImageBatch", err)
+}
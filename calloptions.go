@@ -0,0 +1,131 @@
+package function_go_sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header carrying the idempotency key of a call.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// CallOption overrides the client configuration for a single call, such as to make calls on behalf of different tenants
+// with the same client. Call options apply to every attempt of the call, including fallbacks.
+type CallOption func(options *callOptions)
+
+// callOptions is the configuration of a single call, set by CallOption functions.
+type callOptions struct {
+	// Headers to add to the request.
+	header http.Header
+
+	// The API key to use instead of the client's credentials, if not empty.
+	apiKey string
+
+	// The idempotency key of the call, if not empty.
+	idempotencyKey string
+
+	// The timeout to use instead of ClientOptions.Timeout, if hasTimeout is true.
+	timeout    time.Duration
+	hasTimeout bool
+}
+
+// WithCallHeader adds a header to the request of a call. It can be repeated to add several values.
+// The API key header cannot be set this way; use WithCallApiKey instead.
+func WithCallHeader(key string, value string) CallOption {
+	return func(options *callOptions) {
+		if options.header == nil {
+			options.header = http.Header{}
+		}
+		options.header.Add(key, value)
+	}
+}
+
+// WithCallApiKey authenticates a call with the given API key, instead of the client's API key or credentials provider.
+func WithCallApiKey(apiKey string) CallOption {
+	return func(options *callOptions) {
+		options.apiKey = apiKey
+	}
+}
+
+// WithIdempotencyKey sets the idempotency key of a call, sent in the Idempotency-Key header, so that the gateway can recognize
+// repeated requests for the same operation, and avoid performing and billing them twice.
+// The same key is sent with every attempt of the call, including automatic retries, as they are all the same operation.
+// Use a new key, such as one from NewIdempotencyKey, for each distinct operation, and reuse it when retrying the call manually.
+func WithIdempotencyKey(key string) CallOption {
+	return func(options *callOptions) {
+		options.idempotencyKey = key
+	}
+}
+
+// NewIdempotencyKey returns a new random idempotency key, as a version 4 UUID.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Returns a copy of ctx which carries a new idempotency key, if the client generates them automatically and ctx carries none.
+func (c *Client) withIdempotencyKey(ctx context.Context) context.Context {
+	if !c.autoIdempotencyKeys {
+		return ctx
+	}
+	if options := callOptionsFrom(ctx); options != nil && options.idempotencyKey != "" {
+		return ctx
+	}
+	return withCallOptions(ctx, []CallOption{WithIdempotencyKey(NewIdempotencyKey())})
+}
+
+// WithCallTimeout sets the maximum duration of a unary call, instead of ClientOptions.Timeout.
+// A zero or negative timeout disables the client's timeout for the call, leaving it only bounded by its context.
+// Like ClientOptions.Timeout, it does not apply to streams.
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(options *callOptions) {
+		options.timeout = timeout
+		options.hasTimeout = true
+	}
+}
+
+// Returns the headers to add to every request of a client, from its user agent and default headers.
+func newDefaultHeader(userAgent string, headers map[string]string) (http.Header, error) {
+	header := http.Header{}
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == "X-Api-Key" {
+			return nil, fmt.Errorf("the API key header cannot be set as a default header")
+		}
+		header.Set(key, value)
+	}
+	if userAgent != "" {
+		header.Set("User-Agent", userAgent)
+	}
+	return header, nil
+}
+
+type callOptionsKey struct{}
+
+// Returns a copy of ctx which carries the call options, on top of any already carried by ctx.
+// If there are no options, ctx is returned as-is.
+func withCallOptions(ctx context.Context, opts []CallOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+
+	options := &callOptions{}
+	if parent := callOptionsFrom(ctx); parent != nil {
+		*options = *parent
+		options.header = parent.header.Clone()
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, options)
+}
+
+// Returns the call options carried by ctx, or nil if there are none.
+func callOptionsFrom(ctx context.Context) *callOptions {
+	options, _ := ctx.Value(callOptionsKey{}).(*callOptions)
+	return options
+}
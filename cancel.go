@@ -0,0 +1,134 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"io"
+)
+
+// ClientCanceledError is returned by requests that were canceled, or attempted, after CancelAll was called on their client.
+var ClientCanceledError = errors.New("the client was canceled")
+
+// CancelAll cancels every in-flight request made by the client, including open streams.
+// After CancelAll is called, any new requests fail immediately with ClientCanceledError, so the client should be discarded
+// and recreated if further requests are needed. This is intended for graceful service shutdown.
+//
+// Each request still respects its own context: a request ends as soon as either its context is done or CancelAll is called.
+// Calling CancelAll more than once has no additional effect.
+func (c *Client) CancelAll() {
+	c.lifecycle.cancel()
+}
+
+// clientLifecycle holds the client-level context that all requests derive from.
+type clientLifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newClientLifecycle() *clientLifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &clientLifecycle{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Derives a context from ctx that is also canceled when the client is canceled.
+// The returned function must be called to release resources once the request is done.
+func (l *clientLifecycle) derive(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel, stop := l.deriveDetachable(ctx)
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Like derive, but additionally returns a function which stops propagating client cancellation without canceling the derived context.
+func (l *clientLifecycle) deriveDetachable(ctx context.Context) (context.Context, context.CancelFunc, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(l.ctx, cancel)
+
+	return ctx, cancel, func() { stop() }
+}
+
+// Returns ClientCanceledError, wrapped as a Connect error, if the client was canceled. Otherwise, err is returned as-is.
+func (l *clientLifecycle) translate(err error) error {
+	if err != nil && l.ctx.Err() != nil {
+		return connect.NewError(connect.CodeCanceled, ClientCanceledError)
+	}
+	return err
+}
+
+// cancelInterceptor makes every request derive its context from the client lifecycle.
+type cancelInterceptor struct {
+	lifecycle *clientLifecycle
+}
+
+func (i *cancelInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if i.lifecycle.ctx.Err() != nil {
+			return nil, i.lifecycle.translate(i.lifecycle.ctx.Err())
+		}
+
+		ctx, release := i.lifecycle.derive(ctx)
+		defer release()
+
+		res, err := next(ctx, req)
+		return res, i.lifecycle.translate(err)
+	}
+}
+
+func (i *cancelInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		ctx, cancel, detach := i.lifecycle.deriveDetachable(ctx)
+		return &cancelableConn{
+			StreamingClientConn: next(ctx, spec),
+			lifecycle:           i.lifecycle,
+			cancel:              cancel,
+			detach:              detach,
+		}
+	}
+}
+
+func (i *cancelInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// cancelableConn reports client cancellation as ClientCanceledError, and releases its derived context once the stream ends or is closed.
+type cancelableConn struct {
+	connect.StreamingClientConn
+
+	lifecycle *clientLifecycle
+	cancel    context.CancelFunc
+	detach    func()
+}
+
+func (c *cancelableConn) Send(msg any) error {
+	if c.lifecycle.ctx.Err() != nil {
+		return c.lifecycle.translate(c.lifecycle.ctx.Err())
+	}
+	return c.lifecycle.translate(c.StreamingClientConn.Send(msg))
+}
+
+func (c *cancelableConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err == nil {
+		return nil
+	}
+
+	// The stream is over, so client cancellation no longer needs to be tracked.
+	// This avoids accumulating callbacks for streams that are never closed explicitly.
+	c.detach()
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	return c.lifecycle.translate(err)
+}
+
+func (c *cancelableConn) CloseResponse() error {
+	defer c.detach()
+	defer c.cancel()
+	return c.StreamingClientConn.CloseResponse()
+}
@@ -0,0 +1,103 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ChatCompleteStreamPersist streams a chat completion while writing each token to w as soon as it is read, such as for audit logging,
+// and returns the full response content once the stream completes successfully. See Persist for how failures are handled.
+//
+// Please refer to the developer docs to find a suitable model to use.
+func (c *Client) ChatCompleteStreamPersist(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, w io.Writer) (string, error) {
+	res, err := c.ChatCompleteStream(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	defer res.TokenStream.Close()
+
+	content, writeFailed, err := res.persist(w)
+	if writeFailed {
+		return content, c.methodError("ChatCompleteStreamPersist", err)
+	}
+	return content, err
+}
+
+// Persist reads the rest of the token stream while writing each token to w as soon as it is read, such as for audit logging,
+// and returns the full response content once the stream completes successfully.
+//
+// If the stream fails or is truncated, the content read so far is returned along with the error.
+// In that case, w has still received every token that was read, including one read along with the error,
+// so the returned partial content always matches what was written.
+// If writing to w fails, the stream is closed, and the content read so far, including the token that could not be written, is returned
+// along with the write error.
+func (r *ChatCompleteStreamResponse) Persist(w io.Writer) (string, error) {
+	content, _, err := r.persist(w)
+	return content, err
+}
+
+// Implements Persist, and also returns whether the error, if any, came from writing to w rather than from the stream.
+func (r *ChatCompleteStreamResponse) persist(w io.Writer) (string, bool, error) {
+	var content strings.Builder
+	for {
+		token, err := r.TokenStream.Read()
+		if errors.Is(err, io.EOF) {
+			return content.String(), false, nil
+		}
+
+		// The token is written before the read error is checked, as a token may be read along with the error that ended the stream.
+		content.WriteString(token)
+		if token != "" {
+			if _, writeErr := io.WriteString(w, token); writeErr != nil {
+				r.TokenStream.Close()
+				return content.String(), true, writeErr
+			}
+		}
+		if err != nil {
+			return content.String(), false, err
+		}
+	}
+}
+
+// CollectCapped reads the rest of the token stream into memory, up to maxBytes bytes, which contains runaway generations.
+// It returns the collected text, and whether the cap was hit, in which case the stream is closed early and the text is truncated to
+// at most maxBytes bytes. The text is never truncated in the middle of a UTF-8 character, so it may be slightly shorter than maxBytes.
+//
+// If the stream fails, the text collected so far is returned along with the error.
+func (r *ChatCompleteStreamResponse) CollectCapped(maxBytes int) (string, bool, error) {
+	var content strings.Builder
+	for {
+		token, err := r.TokenStream.Read()
+		if errors.Is(err, io.EOF) {
+			return content.String(), false, nil
+		}
+
+		if content.Len()+len(token) > maxBytes {
+			content.WriteString(truncateUtf8(token, maxBytes-content.Len()))
+			return content.String(), true, r.TokenStream.Close()
+		}
+
+		content.WriteString(token)
+		if err != nil {
+			return content.String(), false, err
+		}
+	}
+}
+
+// Returns the longest prefix of text that is at most n bytes long and does not end in the middle of a UTF-8 character.
+func truncateUtf8(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(text) {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
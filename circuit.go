@@ -0,0 +1,225 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker configures a circuit breaker, which stops sending calls to a degraded gateway for a while,
+// rather than letting every call wait for it to fail. This protects batch workloads from hammering a gateway that is already struggling.
+//
+// The breaker opens after FailureThreshold consecutive failed calls, at which point calls fail immediately with a *CircuitOpenError.
+// Once Cooldown has passed, it half-opens: a single trial call is let through, while other calls keep failing immediately.
+// If the trial call succeeds, the breaker closes and calls flow normally again; otherwise, it opens for another cooldown.
+// Calls which fail for reasons unrelated to the gateway's health, such as invalid arguments, count as successes.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed calls that opens the breaker. It must be at least 1.
+	FailureThreshold int
+
+	// Cooldown is how long the breaker stays open before letting a trial call through. It must be positive.
+	Cooldown time.Duration
+
+	// FailureCodes are the error codes that count as failures.
+	// If unspecified, calls failing with connect.CodeUnavailable, connect.CodeDeadlineExceeded or connect.CodeInternal are failures.
+	FailureCodes []connect.Code
+}
+
+// CircuitOpenError is returned, without making a request, for calls made while the circuit breaker is open.
+type CircuitOpenError struct {
+	// Until is when the breaker half-opens, at which point a trial call is let through.
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("the circuit breaker is open until %s", e.Until.Format(time.RFC3339))
+}
+
+// The state of a circuit breaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks consecutive failures and decides whether calls may proceed.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	threshold    int
+	cooldown     time.Duration
+	failureCodes []connect.Code
+
+	state    circuitState
+	failures int
+
+	// When the breaker half-opens, while it is open.
+	openUntil time.Time
+}
+
+// Validates the options and creates a breaker, or returns nil if options is nil.
+func newCircuitBreaker(options *CircuitBreaker) (*circuitBreaker, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.FailureThreshold < 1 {
+		return nil, fmt.Errorf("circuit breaker failure threshold %d must be at least 1", options.FailureThreshold)
+	}
+	if options.Cooldown <= 0 {
+		return nil, fmt.Errorf("circuit breaker cooldown %v must be positive", options.Cooldown)
+	}
+
+	failureCodes := options.FailureCodes
+	if len(failureCodes) == 0 {
+		failureCodes = []connect.Code{connect.CodeUnavailable, connect.CodeDeadlineExceeded, connect.CodeInternal}
+	}
+
+	return &circuitBreaker{
+		threshold:    options.FailureThreshold,
+		cooldown:     options.Cooldown,
+		failureCodes: failureCodes,
+	}, nil
+}
+
+// Returns nil if a call may proceed, in which case its outcome must be reported with record, or a *CircuitOpenError otherwise.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Now().Before(b.openUntil) {
+			return &CircuitOpenError{Until: b.openUntil}
+		}
+		b.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		// A trial call is already in flight.
+		return &CircuitOpenError{Until: b.openUntil}
+	default:
+		return nil
+	}
+}
+
+// Records the outcome of a call that was allowed to proceed.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	code := connect.CodeOf(err)
+	switch {
+	case err != nil && slices.Contains(b.failureCodes, code):
+		b.failures++
+		if b.state == circuitHalfOpen || b.failures >= b.threshold {
+			b.state = circuitOpen
+			b.openUntil = time.Now().Add(b.cooldown)
+		}
+	case code == connect.CodeCanceled || errors.Is(err, context.Canceled):
+		// The caller gave up, which says nothing about the gateway, but a trial call must still end.
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+	default:
+		b.failures = 0
+		b.state = circuitClosed
+	}
+}
+
+// circuitBreakerInterceptor fails calls immediately while the breaker is open, and reports the outcome of the others to it.
+// Streams are allowed when their request is sent, and report their outcome once they are closed.
+type circuitBreakerInterceptor struct {
+	breaker *circuitBreaker
+}
+
+func (i *circuitBreakerInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if i.breaker == nil {
+			return next(ctx, req)
+		}
+		if err := i.breaker.allow(); err != nil {
+			return nil, err
+		}
+
+		res, err := next(ctx, req)
+		i.breaker.record(err)
+		return res, err
+	}
+}
+
+func (i *circuitBreakerInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if i.breaker == nil {
+			return conn
+		}
+
+		return &circuitBreakerConn{
+			StreamingClientConn: conn,
+			breaker:             i.breaker,
+		}
+	}
+}
+
+func (i *circuitBreakerInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// circuitBreakerConn checks the breaker before sending the request message of a stream, and reports the outcome once the stream ends
+// or is closed, whichever comes first, so that streams read to their end without being closed are still recorded.
+type circuitBreakerConn struct {
+	connect.StreamingClientConn
+
+	breaker *circuitBreaker
+	allowed bool
+
+	// The error that ended the stream, reported to the breaker once it ends.
+	err error
+}
+
+func (c *circuitBreakerConn) Send(msg any) error {
+	if !c.allowed {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+		c.allowed = true
+	}
+
+	err := c.StreamingClientConn.Send(msg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.err = err
+	}
+	return err
+}
+
+func (c *circuitBreakerConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			c.err = err
+		}
+		c.record()
+	}
+	return err
+}
+
+func (c *circuitBreakerConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+	c.record()
+	return err
+}
+
+// Reports the outcome of the stream to the breaker, once.
+func (c *circuitBreakerConn) record() {
+	if !c.allowed {
+		return
+	}
+	c.allowed = false
+	c.breaker.record(c.err)
+}
@@ -0,0 +1,68 @@
+package function_go_sdk
+
+import "connectrpc.com/connect"
+
+// Codec is the message encoding used on the wire when talking to the API gateway.
+type Codec int
+
+const (
+	// CodecProto encodes messages as binary protobuf.
+	// This is the default, and is the most compact and efficient encoding.
+	CodecProto Codec = iota
+
+	// CodecJson encodes messages as protobuf JSON.
+	// JSON is larger and slower to encode and decode than binary protobuf, but is human-readable,
+	// which helps when inspecting traffic or going through intermediaries that only support JSON.
+	CodecJson
+)
+
+// Returns the Connect client options needed to use the codec.
+func (c Codec) connectOptions() []connect.ClientOption {
+	if c == CodecJson {
+		return []connect.ClientOption{connect.WithProtoJSON()}
+	}
+	return nil
+}
+
+// Protocol is the wire protocol used to talk to the API gateway.
+// Gateways serve all of them, so the choice only matters for what the network between the client and the gateway lets through.
+type Protocol int
+
+const (
+	// ProtocolConnect is the Connect protocol, which works over HTTP/1.1 and HTTP/2, and uses plain HTTP semantics for unary calls.
+	// This is the default, and passes through most proxies and load balancers.
+	ProtocolConnect Protocol = iota
+
+	// ProtocolGrpc is the gRPC protocol, for environments that only pass gRPC traffic, such as gRPC-aware load balancers.
+	// It requires HTTP/2, which the default HTTP client only negotiates over https;
+	// to use it over plain http, set ClientOptions.HttpClient to a client which speaks HTTP/2 without TLS.
+	ProtocolGrpc
+
+	// ProtocolGrpcWeb is the gRPC-Web protocol, which works over HTTP/1.1, for environments that only pass gRPC-Web traffic,
+	// such as some proxies and mobile backends.
+	ProtocolGrpcWeb
+)
+
+// Returns the Connect client options needed to use the protocol.
+func (p Protocol) connectOptions() []connect.ClientOption {
+	switch p {
+	case ProtocolGrpc:
+		return []connect.ClientOption{connect.WithGRPC()}
+	case ProtocolGrpcWeb:
+		return []connect.ClientOption{connect.WithGRPCWeb()}
+	default:
+		return nil
+	}
+}
+
+// CompressionGzip is the name of the gzip compression, the only one supported without additional configuration.
+const CompressionGzip = "gzip"
+
+// Returns the Connect client options needed to compress requests with the named compression, if any,
+// once they are at least minBytes long.
+func compressionConnectOptions(name string, minBytes int) []connect.ClientOption {
+	if name == "" {
+		return nil
+	}
+	return []connect.ClientOption{connect.WithSendCompression(name), connect.WithCompressMinBytes(minBytes)}
+}
@@ -0,0 +1,72 @@
+package function_go_sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// clientOptionsFile is the JSON schema read by LoadClientOptions.
+type clientOptionsFile struct {
+	ApiKey  string `json:"apiKey"`
+	BaseUrl string `json:"baseUrl"`
+	Timeout string `json:"timeout"`
+	Codec   string `json:"codec"`
+}
+
+// LoadClientOptions reads client options from a JSON config file, for use with NewClient.
+// The file is a single JSON object with the following fields:
+//
+//	{
+//	  "apiKey": "${FUNCTION_API_KEY}", // Required. The API key.
+//	  "baseUrl": "https://...",        // Optional. The API gateway base URL.
+//	  "timeout": "30s",                // Optional. The unary request timeout, as a Go duration or a number of seconds.
+//	  "codec": "proto"                 // Optional. The wire codec, either "proto" or "json".
+//	}
+//
+// References to environment variables in the form $NAME or ${NAME} are expanded in apiKey and baseUrl,
+// so that secrets do not need to be stored in the file itself.
+// Options that cannot be serialized, such as HttpClient, must be set on the returned options by the caller.
+//
+// If the API key is missing or expands to an empty string, MissingApiKeyError is returned.
+// Unknown fields are rejected to catch typos.
+func LoadClientOptions(path string) (ClientOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ClientOptions{}, err
+	}
+
+	var file clientOptionsFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return ClientOptions{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	options := ClientOptions{
+		ApiKey:  os.ExpandEnv(file.ApiKey),
+		BaseUrl: os.ExpandEnv(file.BaseUrl),
+	}
+	if options.ApiKey == "" {
+		return ClientOptions{}, MissingApiKeyError
+	}
+
+	if file.Timeout != "" {
+		timeout, err := parseTimeout(file.Timeout)
+		if err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid timeout %q in config file %s: %w", file.Timeout, path, err)
+		}
+		options.Timeout = timeout
+	}
+
+	if file.Codec != "" {
+		codec, err := parseCodec(file.Codec)
+		if err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid codec %q in config file %s: %w", file.Codec, path, err)
+		}
+		options.Codec = codec
+	}
+
+	return options, nil
+}
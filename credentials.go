@@ -0,0 +1,118 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// CredentialsProvider supplies the API key used to authenticate each call, such as from a secret manager,
+// so that keys can be rotated at runtime without recreating the client.
+// Token is called once per call, including each attempt of a call with fallbacks, so implementations should cache keys
+// rather than fetch them every time. It must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Token returns the API key to authenticate a call with. ctx is the context of the call.
+	// If it fails, the call fails with connect.CodeUnauthenticated, wrapping the returned error.
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticCredentials returns a CredentialsProvider which always returns the same API key.
+func StaticCredentials(apiKey string) CredentialsProvider {
+	return staticCredentials(apiKey)
+}
+
+type staticCredentials string
+
+func (c staticCredentials) Token(ctx context.Context) (string, error) {
+	return string(c), nil
+}
+
+// authInterceptor sets the API key header of every request, including streams, to the key returned by the credentials provider,
+// or to the key set by WithCallApiKey. It also adds the default headers of the client, and the headers set by WithCallHeader and WithIdempotencyKey.
+type authInterceptor struct {
+	credentials CredentialsProvider
+
+	// Headers added to every request, unless a call header of the same name is set.
+	header http.Header
+}
+
+// Sets the API key header, default headers and call headers, or returns an error if no key could be obtained.
+func (i *authInterceptor) authenticate(ctx context.Context, header http.Header) error {
+	options := callOptionsFrom(ctx)
+	for key, values := range i.header {
+		if options == nil || len(options.header.Values(key)) == 0 {
+			header[key] = slices.Clone(values)
+		}
+	}
+	if options != nil {
+		for key, values := range options.header {
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+		if options.idempotencyKey != "" {
+			header.Set(IdempotencyKeyHeader, options.idempotencyKey)
+		}
+		if options.apiKey != "" {
+			header.Set("x-api-key", options.apiKey)
+			return nil
+		}
+	}
+
+	token, err := i.credentials.Token(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeUnauthenticated, fmt.Errorf("could not get credentials: %w", err))
+	}
+	if token == "" {
+		return connect.NewError(connect.CodeUnauthenticated, MissingApiKeyError)
+	}
+
+	header.Set("x-api-key", token)
+	return nil
+}
+
+func (i *authInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.authenticate(ctx, req.Header()); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func (i *authInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		return &authenticatedConn{
+			StreamingClientConn: next(ctx, spec),
+			ctx:                 ctx,
+			interceptor:         i,
+		}
+	}
+}
+
+func (i *authInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// authenticatedConn sets the API key header before the request message of a stream is sent, as headers are sent along with it.
+type authenticatedConn struct {
+	connect.StreamingClientConn
+
+	ctx           context.Context
+	interceptor   *authInterceptor
+	authenticated bool
+}
+
+func (c *authenticatedConn) Send(msg any) error {
+	if !c.authenticated {
+		if err := c.interceptor.authenticate(c.ctx, c.RequestHeader()); err != nil {
+			return err
+		}
+		c.authenticated = true
+	}
+
+	return c.StreamingClientConn.Send(msg)
+}
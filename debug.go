@@ -0,0 +1,156 @@
+package function_go_sdk
+
+import (
+	"bytes"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Options used to encode messages in debug dumps.
+var debugJsonOptions = protojson.MarshalOptions{Multiline: true, Indent: "  "}
+
+// debugInterceptor dumps every request and response, with their headers and messages as protobuf JSON, for troubleshooting.
+// It runs innermost, so that it dumps requests as they are sent, after all other interceptors have modified them.
+type debugInterceptor struct {
+	// The writer to dump to. If nil, nothing is dumped.
+	out io.Writer
+
+	// Guards out, so that dumps of concurrent requests are not interleaved.
+	mu sync.Mutex
+}
+
+// Writes a dump, made of a title line, headers and a message, in a single write.
+func (i *debugInterceptor) dump(title string, header http.Header, msg any) {
+	var buf bytes.Buffer
+	buf.WriteString(title + "\n")
+	redactHeader(header).Write(&buf)
+	if message, ok := msg.(proto.Message); ok {
+		if encoded, err := debugJsonOptions.Marshal(message); err == nil {
+			buf.Write(encoded)
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString("\n")
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.out.Write(buf.Bytes())
+}
+
+// Dumps an error, with its code and metadata.
+func (i *debugInterceptor) dumpError(procedure string, start time.Time, err error) {
+	title := fmt.Sprintf("<-- %s %s (%s): %v", procedure, connect.CodeOf(err), time.Since(start), err)
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		i.dump(title, connectErr.Meta(), nil)
+	} else {
+		i.dump(title, nil, nil)
+	}
+}
+
+func (i *debugInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if i.out == nil {
+			return next(ctx, req)
+		}
+
+		procedure := req.Spec().Procedure
+		start := time.Now()
+		i.dump("--> "+procedure, req.Header(), req.Any())
+
+		res, err := next(ctx, req)
+		if err != nil {
+			i.dumpError(procedure, start, err)
+			return res, err
+		}
+
+		i.dump(fmt.Sprintf("<-- %s ok (%s)", procedure, time.Since(start)), res.Header(), res.Any())
+		return res, err
+	}
+}
+
+func (i *debugInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if i.out == nil {
+			return conn
+		}
+
+		return &debugConn{StreamingClientConn: conn, interceptor: i}
+	}
+}
+
+func (i *debugInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// debugConn dumps the request of a stream, its response headers along with its first chunk, each following chunk,
+// and how the stream ended.
+type debugConn struct {
+	connect.StreamingClientConn
+
+	interceptor *debugInterceptor
+	start       time.Time
+
+	// The number of chunks received.
+	chunks int
+	ended  bool
+}
+
+func (c *debugConn) Send(msg any) error {
+	c.start = time.Now()
+	c.interceptor.dump("--> "+c.Spec().Procedure, c.RequestHeader(), msg)
+
+	err := c.StreamingClientConn.Send(msg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.end(err)
+	}
+	return err
+}
+
+func (c *debugConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err != nil {
+		c.end(err)
+		return err
+	}
+
+	c.chunks++
+	var header http.Header
+	if c.chunks == 1 {
+		header = c.ResponseHeader()
+	}
+	c.interceptor.dump(fmt.Sprintf("<-- %s chunk %d (%s)", c.Spec().Procedure, c.chunks, time.Since(c.start)), header, msg)
+	return nil
+}
+
+func (c *debugConn) CloseResponse() error {
+	c.end(nil)
+	return c.StreamingClientConn.CloseResponse()
+}
+
+// Dumps how the stream ended, once: read to its end, failed with err, or closed.
+func (c *debugConn) end(err error) {
+	if c.ended || c.start.IsZero() {
+		return
+	}
+	c.ended = true
+
+	procedure := c.Spec().Procedure
+	switch {
+	case err == nil:
+		c.interceptor.dump(fmt.Sprintf("<-- %s closed after %d chunks (%s)", procedure, c.chunks, time.Since(c.start)), nil, nil)
+	case errors.Is(err, io.EOF):
+		c.interceptor.dump(fmt.Sprintf("<-- %s end after %d chunks (%s)", procedure, c.chunks, time.Since(c.start)), c.ResponseTrailer(), nil)
+	default:
+		c.interceptor.dumpError(procedure, c.start, err)
+	}
+}
@@ -0,0 +1,121 @@
+package function_go_sdk
+
+import "strings"
+
+// DiffKind is the kind of a DiffOp.
+type DiffKind int
+
+const (
+	// DiffEqual marks words present in both responses.
+	DiffEqual DiffKind = iota
+
+	// DiffDelete marks words only present in the first response.
+	DiffDelete
+
+	// DiffInsert marks words only present in the second response.
+	DiffInsert
+)
+
+// DiffOp is a run of consecutive words which are equal, deleted, or inserted.
+type DiffOp struct {
+	Kind DiffKind
+
+	// Words are the words of the run, in order.
+	Words []string
+}
+
+// DiffResult is the word-level difference between two responses, as returned by DiffResponses.
+type DiffResult struct {
+	// Ops are the runs of words which transform the first response into the second, in order.
+	// Applying them, by keeping equal and inserted words and dropping deleted ones, yields the words of the second response.
+	Ops []DiffOp
+
+	// Similarity is the fraction of words shared by both responses, from 0 for entirely different responses to 1 for responses
+	// with the same words. It is twice the number of equal words divided by the total number of words in both responses.
+	Similarity float64
+}
+
+// Equal returns whether both responses have the same words.
+func (r DiffResult) Equal() bool {
+	for _, op := range r.Ops {
+		if op.Kind != DiffEqual {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the diff in the style of git's word diff, with deleted words as [-words-] and inserted words as {+words+}.
+func (r DiffResult) String() string {
+	parts := make([]string, len(r.Ops))
+	for i, op := range r.Ops {
+		words := strings.Join(op.Words, " ")
+		switch op.Kind {
+		case DiffDelete:
+			parts[i] = "[-" + words + "-]"
+		case DiffInsert:
+			parts[i] = "{+" + words + "+}"
+		default:
+			parts[i] = words
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// DiffResponses compares two responses word by word, such as the outputs of two model versions for the same prompt,
+// which helps detect when a model update changes outputs in prompt regression suites.
+// Words are separated by whitespace, and differences in whitespace alone are ignored.
+//
+// The diff is based on the longest common subsequence of words, so it takes time and memory proportional to the product of
+// the word counts of both responses. This is fine for chat responses, but may be slow for very long documents.
+func DiffResponses(a string, b string) DiffResult {
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of wordsA[i:] and wordsB[j:].
+	lcs := make([][]int, len(wordsA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(wordsB)+1)
+	}
+	for i := len(wordsA) - 1; i >= 0; i-- {
+		for j := len(wordsB) - 1; j >= 0; j-- {
+			if wordsA[i] == wordsB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result DiffResult
+	i, j := 0, 0
+	for i < len(wordsA) || j < len(wordsB) {
+		switch {
+		case i < len(wordsA) && j < len(wordsB) && wordsA[i] == wordsB[j]:
+			result.add(DiffEqual, wordsA[i])
+			i++
+			j++
+		case j == len(wordsB) || (i < len(wordsA) && lcs[i+1][j] >= lcs[i][j+1]):
+			result.add(DiffDelete, wordsA[i])
+			i++
+		default:
+			result.add(DiffInsert, wordsB[j])
+			j++
+		}
+	}
+
+	result.Similarity = 1
+	if total := len(wordsA) + len(wordsB); total > 0 {
+		result.Similarity = float64(2*lcs[0][0]) / float64(total)
+	}
+	return result
+}
+
+// Appends a word to the last run if it has the same kind, or to a new run otherwise.
+func (r *DiffResult) add(kind DiffKind, word string) {
+	if len(r.Ops) > 0 && r.Ops[len(r.Ops)-1].Kind == kind {
+		r.Ops[len(r.Ops)-1].Words = append(r.Ops[len(r.Ops)-1].Words, word)
+		return
+	}
+	r.Ops = append(r.Ops, DiffOp{Kind: kind, Words: []string{word}})
+}
@@ -0,0 +1,292 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NoInputsError is returned by Embed and EmbedBatch when there is nothing to embed, unless ClientOptions.AllowEmptyEmbedInput is set.
+var NoInputsError = errors.New("no embedding input was given")
+
+// InconsistentDimensionError is returned when the vectors in a batch of embeddings do not all have the same number of dimensions.
+// This can be indicative of mixed models or an API gateway malfunction.
+type InconsistentDimensionError struct {
+	// Index is the index of the first input whose vector did not match the dimensions of the first non-empty vector.
+	Index int
+
+	// Expected is the number of dimensions of the first non-empty vector in the batch.
+	Expected int
+
+	// Actual is the number of dimensions of the vector at Index.
+	Actual int
+}
+
+func (e *InconsistentDimensionError) Error() string {
+	return fmt.Sprintf("embedding %d has %d dimensions, expected %d", e.Index, e.Actual, e.Expected)
+}
+
+// EmbedBatch generates vector embeddings for each of the inputs using the given model.
+// The returned vectors are in the same order as the inputs.
+// One request is made per input, and the first error encountered is returned.
+//
+// If there are no inputs, NoInputsError is returned, unless ClientOptions.AllowEmptyEmbedInput is set.
+// With that option, empty inputs within a batch are allowed too, and get an empty vector, as Embed returns no vector for them.
+//
+// All non-empty vectors are verified to have the same number of dimensions.
+// If they do not, an *InconsistentDimensionError is returned rather than a ragged result.
+//
+// Please refer to the developer docs to find a suitable model to use.
+func (c *Client) EmbedBatch(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 && !c.allowEmptyEmbedInput {
+		return nil, c.methodError("EmbedBatch", NoInputsError)
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		res, err := c.Embed(ctx, &apigatewayv1.EmbedRequest{
+			Model: model,
+			Input: input,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		vectors[i] = firstEmbedding(res)
+	}
+
+	if err := checkDimensions(vectors); err != nil {
+		return nil, c.methodError("EmbedBatch", err)
+	}
+
+	return vectors, nil
+}
+
+// EmbedBatchPartial is like EmbedBatch, but returns the vectors that were completed even if the batch did not finish,
+// such as because ctx was canceled, so that ingestion jobs can checkpoint their progress and later resume without redoing completed work.
+//
+// The result is sparse: it is keyed by input index, and holds a vector for each input that completed, and none for the others.
+// Inputs are embedded in order, one request at a time. If ctx is canceled or its deadline passes, the batch stops,
+// and the vectors completed so far are returned along with an error for which errors.Is reports context.Canceled
+// or context.DeadlineExceeded. If a request fails for any other reason, the batch also stops, and the completed vectors
+// are returned along with that error. If a vector does not have the same number of dimensions as the first non-empty one,
+// the batch stops with an *InconsistentDimensionError, and the mismatched vector is left out.
+// Empty inputs, if allowed by ClientOptions.AllowEmptyEmbedInput, complete with an empty vector.
+//
+// Please refer to the developer docs to find a suitable model to use.
+func (c *Client) EmbedBatchPartial(ctx context.Context, model string, inputs []string) (map[int][]float32, error) {
+	if len(inputs) == 0 && !c.allowEmptyEmbedInput {
+		return nil, c.methodError("EmbedBatchPartial", NoInputsError)
+	}
+
+	vectors := make(map[int][]float32, len(inputs))
+	dimensions := -1
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return vectors, c.methodError("EmbedBatchPartial", err)
+		}
+
+		res, err := c.Embed(ctx, &apigatewayv1.EmbedRequest{
+			Model: model,
+			Input: input,
+		})
+		if err != nil {
+			// A request interrupted by cancellation fails with a Connect error, so the context error is reported instead.
+			if ctx.Err() != nil {
+				return vectors, c.methodError("EmbedBatchPartial", ctx.Err())
+			}
+			return vectors, err
+		}
+
+		vector := firstEmbedding(res)
+		if dimensions < 0 && len(vector) > 0 {
+			dimensions = len(vector)
+		}
+		if len(vector) > 0 && len(vector) != dimensions {
+			return vectors, c.methodError("EmbedBatchPartial", &InconsistentDimensionError{
+				Index:    i,
+				Expected: dimensions,
+				Actual:   len(vector),
+			})
+		}
+		vectors[i] = vector
+	}
+
+	return vectors, nil
+}
+
+// Returns the embedding vector from a response to a single-input request.
+func firstEmbedding(res *apigatewayv1.EmbedResponse) []float32 {
+	if len(res.Data) == 0 {
+		return nil
+	}
+	return res.Data[0].Embedding
+}
+
+// Verifies that all non-empty vectors have the same number of dimensions as the first one.
+// Empty vectors are those of empty inputs, which Embed returns no vector for if ClientOptions.AllowEmptyEmbedInput is set.
+func checkDimensions(vectors [][]float32) error {
+	dimensions := -1
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			continue
+		}
+		if dimensions < 0 {
+			dimensions = len(vector)
+		}
+		if len(vector) != dimensions {
+			return &InconsistentDimensionError{
+				Index:    i,
+				Expected: dimensions,
+				Actual:   len(vector),
+			}
+		}
+	}
+	return nil
+}
+
+// ThroughputReport summarizes the results of BenchmarkEmbed.
+type ThroughputReport struct {
+	// Duration is how long the benchmark ran for.
+	Duration time.Duration
+
+	// Requests is the number of requests made, including failed ones.
+	Requests int
+
+	// Errors is the number of requests that failed.
+	Errors int
+
+	// Tokens is the total number of tokens processed by successful requests, as reported by the API gateway.
+	Tokens int
+
+	// RequestsPerSecond is the number of requests completed per second, including failed ones.
+	RequestsPerSecond float64
+
+	// TokensPerSecond is the number of tokens processed per second.
+	TokensPerSecond float64
+
+	// ErrorRate is the fraction of requests that failed, between 0 and 1.
+	ErrorRate float64
+
+	// P50Latency, P95Latency and P99Latency are latency percentiles over all requests, including failed ones.
+	P50Latency time.Duration
+	P95Latency time.Duration
+	P99Latency time.Duration
+}
+
+// BenchmarkEmbed repeatedly embeds the sample inputs, one request at a time and cycling through them, for the given duration,
+// and reports the achieved throughput and latency. This helps size ingestion pipelines.
+// Requests go through the client as usual, so any configured rate limiter is respected.
+//
+// Note that the benchmark makes real requests, and therefore consumes quota.
+//
+// If ctx is done before the duration elapses, the benchmark stops early and returns the report so far along with the context error.
+func (c *Client) BenchmarkEmbed(ctx context.Context, model string, sampleInputs []string, duration time.Duration) (*ThroughputReport, error) {
+	if len(sampleInputs) == 0 {
+		return nil, c.methodError("BenchmarkEmbed", errors.New("at least one sample input is required"))
+	}
+
+	benchCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	report := &ThroughputReport{}
+	var latencies []time.Duration
+	startedAt := time.Now()
+
+	for i := 0; benchCtx.Err() == nil; i++ {
+		requestStartedAt := time.Now()
+		res, err := c.Embed(benchCtx, &apigatewayv1.EmbedRequest{
+			Model: model,
+			Input: sampleInputs[i%len(sampleInputs)],
+		})
+
+		// A request cut short by the end of the benchmark says nothing about the gateway, so it is not counted.
+		if err != nil && benchCtx.Err() != nil {
+			break
+		}
+
+		latencies = append(latencies, time.Since(requestStartedAt))
+		report.Requests++
+		if err != nil {
+			report.Errors++
+		} else {
+			report.Tokens += int(res.GetUsage().GetTotalTokens())
+		}
+	}
+
+	report.Duration = time.Since(startedAt)
+	if report.Requests > 0 {
+		seconds := report.Duration.Seconds()
+		report.RequestsPerSecond = float64(report.Requests) / seconds
+		report.TokensPerSecond = float64(report.Tokens) / seconds
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+
+		slices.Sort(latencies)
+		report.P50Latency = percentile(latencies, 0.50)
+		report.P95Latency = percentile(latencies, 0.95)
+		report.P99Latency = percentile(latencies, 0.99)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, c.methodError("BenchmarkEmbed", err)
+	}
+	return report, nil
+}
+
+// Returns the p-th percentile, with p between 0 and 1, of a non-empty sorted list of durations, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// NormalizeEmbedInput is a built-in embedding input normalizer, for use as ClientOptions.EmbedInputNormalizer.
+// It applies Unicode NFC normalization, collapses every run of whitespace into a single space, and trims leading and trailing whitespace.
+// It does not change letter case; to also lowercase inputs, use a normalizer such as:
+//
+//	func(input string) string { return strings.ToLower(NormalizeEmbedInput(input)) }
+func NormalizeEmbedInput(input string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(input)), " ")
+}
+
+// DType is the data type in which embedding vector components are stored.
+type DType int
+
+const (
+	// DTypeFloat32 stores each component as a 32-bit float, which is how the API returns embeddings.
+	DTypeFloat32 DType = iota
+
+	// DTypeFloat64 stores each component as a 64-bit float.
+	DTypeFloat64
+
+	// DTypeInt8 stores each component as an 8-bit integer, such as after scalar quantization.
+	DTypeInt8
+)
+
+// Size returns the number of bytes taken by a single component of this data type.
+func (d DType) Size() int {
+	switch d {
+	case DTypeFloat64:
+		return 8
+	case DTypeInt8:
+		return 1
+	default:
+		return 4
+	}
+}
+
+// EstimateEmbeddingStorage returns the number of bytes needed to store numVectors embeddings of the given dimensions,
+// with components of the given data type, such as for sizing a vector database.
+// This only accounts for the raw vector data: indexes, IDs, and metadata stored by the database come on top of it.
+// If numVectors or dimensions is negative, zero is returned.
+func EstimateEmbeddingStorage(numVectors int, dimensions int, dtype DType) int64 {
+	if numVectors < 0 || dimensions < 0 {
+		return 0
+	}
+	return int64(numVectors) * int64(dimensions) * int64(dtype.Size())
+}
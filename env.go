@@ -0,0 +1,109 @@
+package function_go_sdk
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ClientOptionsFromEnv.
+const (
+	// EnvApiKey holds the API key. Required.
+	EnvApiKey = "FUNCTION_API_KEY"
+
+	// EnvBaseUrl holds the API gateway base URL. Optional.
+	EnvBaseUrl = "FUNCTION_BASE_URL"
+
+	// EnvTimeout holds the unary request timeout, either as a Go duration such as "30s", or as a number of seconds. Optional.
+	EnvTimeout = "FUNCTION_TIMEOUT"
+
+	// EnvCodec holds the wire codec, either "proto" or "json". Optional.
+	EnvCodec = "FUNCTION_CODEC"
+
+	// EnvProxyUrl holds the URL of a proxy to send all requests through, such as "http://proxy.internal:3128" or "socks5://proxy.internal:1080",
+	// with the schemes supported by ClientOptions.ProxyUrl, which it sets. Optional.
+	// Unlike HTTPS_PROXY, which is honored by default, it only applies to the Function client, and not to the rest of the process.
+	EnvProxyUrl = "FUNCTION_PROXY_URL"
+)
+
+// ClientOptionsFromEnv creates client options from the FUNCTION_* environment variables, for use with NewClient.
+// See EnvApiKey and the other Env constants for the supported variables and their formats.
+// Variables that are unset or empty are left at their defaults.
+//
+// If FUNCTION_API_KEY is unset or empty, an error wrapping MissingApiKeyError is returned.
+// If any variable is set to an invalid value, an error naming that variable is returned.
+func ClientOptionsFromEnv() (ClientOptions, error) {
+	options := ClientOptions{
+		ApiKey:  os.Getenv(EnvApiKey),
+		BaseUrl: os.Getenv(EnvBaseUrl),
+	}
+	if options.ApiKey == "" {
+		return ClientOptions{}, fmt.Errorf("%s is not set: %w", EnvApiKey, MissingApiKeyError)
+	}
+
+	if value := os.Getenv(EnvTimeout); value != "" {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid %s %q: %w", EnvTimeout, value, err)
+		}
+		options.Timeout = timeout
+	}
+
+	if value := os.Getenv(EnvCodec); value != "" {
+		codec, err := parseCodec(value)
+		if err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid %s %q: %w", EnvCodec, value, err)
+		}
+		options.Codec = codec
+	}
+
+	if value := os.Getenv(EnvProxyUrl); value != "" {
+		if _, err := parseProxyUrl(value); err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid %s %q: %w", EnvProxyUrl, value, err)
+		}
+		options.ProxyUrl = value
+	}
+
+	return options, nil
+}
+
+// NewClientFromEnv creates a new Function Network client configured from the FUNCTION_* environment variables.
+// It is equivalent to calling NewClient with the options returned by ClientOptionsFromEnv, and fails in the same cases.
+func NewClientFromEnv() (*Client, error) {
+	options, err := ClientOptionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(options)
+}
+
+// Parses a timeout given either as a Go duration, or as a number of seconds.
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		value = fmt.Sprintf("%gs", seconds)
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("timeout must not be negative")
+	}
+	return timeout, nil
+}
+
+// Parses a codec name, case-insensitively.
+func parseCodec(value string) (Codec, error) {
+	switch strings.ToLower(value) {
+	case "proto":
+		return CodecProto, nil
+	case "json":
+		return CodecJson, nil
+	default:
+		return 0, fmt.Errorf(`codec must be "proto" or "json"`)
+	}
+}
@@ -0,0 +1,316 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MethodError is returned by client methods when a call fails, and identifies the method that produced the error.
+// The underlying error is preserved, so errors.Is and errors.As can be used to inspect it.
+type MethodError struct {
+	// Method is the name of the client method that failed, such as "ChatComplete".
+	Method string
+
+	// Code is the Connect error code of the underlying error.
+	// If the error did not come from Connect, such as a client-side validation error, it is connect.CodeUnknown.
+	Code connect.Code
+
+	// RequestId is the ID the gateway assigned to the failed request, from RequestIdHeader, to quote when reporting issues.
+	// It is empty if the gateway did not assign one, such as when the call failed before reaching it.
+	RequestId string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *MethodError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Method, e.Err)
+}
+
+func (e *MethodError) Unwrap() error {
+	return e.Err
+}
+
+// Wraps err in a *MethodError for the given method. A nil error is returned as-is.
+func wrapMethodError(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var requestId string
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		requestId = connectErr.Meta().Get(RequestIdHeader)
+	}
+
+	return &MethodError{
+		Method:    method,
+		Code:      connect.CodeOf(err),
+		RequestId: requestId,
+		Err:       err,
+	}
+}
+
+// Wraps err in a *MethodError for the given method, and then applies the client's ErrorWrapper, if any. A nil error is returned as-is.
+func (c *Client) methodError(method string, err error) error {
+	err = wrapMethodError(method, err)
+	if err == nil || c.errorWrapper == nil {
+		return err
+	}
+
+	if wrapped := c.errorWrapper(method, err); wrapped != nil {
+		return wrapped
+	}
+	return err
+}
+
+// RequestIdHeader is the header in which the gateway returns the ID it assigned to a request, to quote when reporting issues.
+const RequestIdHeader = "X-Request-Id"
+
+// ApiError is implemented by the errors returned when a call is rejected, so that callers can decide how to handle a failure,
+// such as whether to retry it, without inspecting error messages. Calls rejected by the gateway fail with one of
+// *AuthenticationError, *RateLimitError, *QuotaExceededError, *ModelNotFoundError, *InvalidRequestError or *ServerError,
+// which can be found with errors.As, either as their own type or as an ApiError. They wrap the underlying *connect.Error, if any.
+// Errors with other codes, such as connect.CodeCanceled or connect.CodeDeadlineExceeded, are returned as-is.
+type ApiError interface {
+	error
+
+	// Code is the Connect error code of the failure.
+	Code() connect.Code
+
+	// RequestId is the ID the gateway assigned to the failed request, from RequestIdHeader, or an empty string if it did not assign one,
+	// such as when the call was rejected by the client itself.
+	RequestId() string
+
+	// Retryable reports whether the call may succeed if it is sent again unchanged, such as after a server error or rate limiting.
+	Retryable() bool
+}
+
+// connectError holds the Connect error a call failed with, and implements the methods of ApiError common to all error types.
+type connectError struct {
+	err *connect.Error
+}
+
+func (e *connectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+func (e *connectError) Code() connect.Code {
+	if e.err == nil {
+		return connect.CodeUnknown
+	}
+	return e.err.Code()
+}
+
+func (e *connectError) RequestId() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Meta().Get(RequestIdHeader)
+}
+
+// AuthenticationError is returned when a call is rejected because the API key is missing, invalid, or not allowed to make it,
+// with connect.CodeUnauthenticated or connect.CodePermissionDenied.
+type AuthenticationError struct {
+	connectError
+}
+
+func (e *AuthenticationError) Retryable() bool {
+	return false
+}
+
+// Headers in which the gateway describes the rate limit that rejected a call.
+const (
+	retryAfterHeader         = "Retry-After"
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitError is returned when a call is rejected because too many requests were sent, with connect.CodeResourceExhausted.
+// It is retryable, after waiting for RetryAfter. RetryPolicy honors RetryAfter, and retries rate-limited calls that carry it.
+type RateLimitError struct {
+	connectError
+
+	// RetryAfter is how long to wait before retrying, from the Retry-After header, which holds either a number of seconds or a date.
+	// If the gateway did not send Retry-After, it is the time until ResetAt. It is 0 if neither is known.
+	RetryAfter time.Duration
+
+	// Limit is the number of requests allowed per window, from the X-RateLimit-Limit header, or 0 if the gateway did not report it.
+	Limit int
+
+	// Remaining is the number of requests left in the current window, from the X-RateLimit-Remaining header.
+	// It is only meaningful if Limit is set.
+	Remaining int
+
+	// ResetAt is when the current window ends, from the X-RateLimit-Reset header, in Unix seconds, or the zero time if the gateway did not report it.
+	ResetAt time.Time
+}
+
+// Creates a *RateLimitError from a Connect error, reading the rate limit from its metadata. Malformed headers are ignored.
+func newRateLimitError(err *connect.Error) *RateLimitError {
+	meta := err.Meta()
+	rateLimitErr := &RateLimitError{connectError: connectError{err: err}}
+	rateLimitErr.Limit, _ = strconv.Atoi(meta.Get(rateLimitLimitHeader))
+	rateLimitErr.Remaining, _ = strconv.Atoi(meta.Get(rateLimitRemainingHeader))
+	if reset, parseErr := strconv.ParseInt(meta.Get(rateLimitResetHeader), 10, 64); parseErr == nil {
+		rateLimitErr.ResetAt = time.Unix(reset, 0)
+	}
+
+	retryAfter := meta.Get(retryAfterHeader)
+	if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil {
+		rateLimitErr.RetryAfter = time.Duration(seconds) * time.Second
+	} else if date, parseErr := http.ParseTime(retryAfter); parseErr == nil {
+		rateLimitErr.RetryAfter = time.Until(date)
+	} else if !rateLimitErr.ResetAt.IsZero() {
+		rateLimitErr.RetryAfter = time.Until(rateLimitErr.ResetAt)
+	}
+	rateLimitErr.RetryAfter = max(rateLimitErr.RetryAfter, 0)
+	return rateLimitErr
+}
+
+func (e *RateLimitError) Retryable() bool {
+	return true
+}
+
+// QuotaExceededError is returned when a call is rejected because the account has used up its quota, with connect.CodeResourceExhausted
+// and a message mentioning the quota. Unlike a *RateLimitError, retrying does not help until the quota is raised or renewed.
+type QuotaExceededError struct {
+	connectError
+}
+
+func (e *QuotaExceededError) Retryable() bool {
+	return false
+}
+
+// ModelNotFoundError is returned when a call is rejected because the requested model does not exist, with connect.CodeNotFound,
+// and by ContextWindow when no limits are known for a model.
+type ModelNotFoundError struct {
+	connectError
+
+	// Model is the model that was not found. For calls, it is the model of the last attempt, which may be a fallback model.
+	Model string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("no limits are known for model %q", e.Model)
+	}
+	return fmt.Sprintf("model %q not found: %v", e.Model, e.err)
+}
+
+func (e *ModelNotFoundError) Code() connect.Code {
+	return connect.CodeNotFound
+}
+
+func (e *ModelNotFoundError) Retryable() bool {
+	return false
+}
+
+// InvalidRequestError is returned when a call is rejected because the request is malformed or unacceptable, such as a prompt that is too long,
+// with connect.CodeInvalidArgument, connect.CodeFailedPrecondition or connect.CodeOutOfRange. The request must be changed before it can succeed.
+type InvalidRequestError struct {
+	connectError
+}
+
+func (e *InvalidRequestError) Retryable() bool {
+	return false
+}
+
+// ServerError is returned when a call fails because of the gateway or a model backend, with connect.CodeInternal, connect.CodeUnavailable,
+// connect.CodeUnknown or connect.CodeDataLoss. Such failures are usually transient, so it is retryable.
+type ServerError struct {
+	connectError
+}
+
+func (e *ServerError) Retryable() bool {
+	return true
+}
+
+// Returns err as the ApiError type matching its code, for a request for the given model.
+// Errors which are not Connect errors, are already an ApiError, or have a code outside the taxonomy are returned as-is.
+func classifyError(err error, model string) error {
+	var connectErr *connect.Error
+	var apiErr ApiError
+	if err == nil || errors.As(err, &apiErr) || !errors.As(err, &connectErr) {
+		return err
+	}
+
+	base := connectError{err: connectErr}
+	switch connectErr.Code() {
+	case connect.CodeUnauthenticated, connect.CodePermissionDenied:
+		return &AuthenticationError{base}
+	case connect.CodeResourceExhausted:
+		if strings.Contains(strings.ToLower(connectErr.Message()), "quota") {
+			return &QuotaExceededError{base}
+		}
+		return newRateLimitError(connectErr)
+	case connect.CodeNotFound:
+		return &ModelNotFoundError{connectError: base, Model: model}
+	case connect.CodeInvalidArgument, connect.CodeFailedPrecondition, connect.CodeOutOfRange:
+		return &InvalidRequestError{base}
+	case connect.CodeInternal, connect.CodeUnavailable, connect.CodeUnknown, connect.CodeDataLoss:
+		return &ServerError{base}
+	default:
+		return err
+	}
+}
+
+// errorInterceptor converts the errors of every request to ApiError types, so that the rest of the client, and callers, see typed errors.
+type errorInterceptor struct{}
+
+func (i *errorInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		res, err := next(ctx, req)
+		return res, classifyError(err, RequestModel(req.Any()))
+	}
+}
+
+func (i *errorInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		return &errorConn{StreamingClientConn: next(ctx, spec)}
+	}
+}
+
+func (i *errorInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// errorConn converts the errors of a stream to ApiError types.
+type errorConn struct {
+	connect.StreamingClientConn
+
+	// The model of the stream's request.
+	model string
+}
+
+func (c *errorConn) Send(msg any) error {
+	c.model = RequestModel(msg)
+	return c.classify(c.StreamingClientConn.Send(msg))
+}
+
+func (c *errorConn) Receive(msg any) error {
+	return c.classify(c.StreamingClientConn.Receive(msg))
+}
+
+// Classifies a stream error, leaving io.EOF, which marks the end of the stream, as-is.
+func (c *errorConn) classify(err error) error {
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+	return classifyError(err, c.model)
+}
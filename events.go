@@ -0,0 +1,60 @@
+package function_go_sdk
+
+// StreamEventKind is the kind of a StreamEvent.
+type StreamEventKind int
+
+const (
+	// StreamEventToken is a token of the response.
+	StreamEventToken StreamEventKind = iota
+
+	// StreamEventRoleChange marks that the tokens that follow belong to a different role than the ones before.
+	StreamEventRoleChange
+)
+
+// StreamEvent is an event read from a chat completion stream with ReadEvent.
+type StreamEvent struct {
+	Kind StreamEventKind
+
+	// Role is the role of the token for token events, and the new role for role change events.
+	Role string
+
+	// Token is the token text. It is empty for role change events.
+	Token string
+}
+
+// ReadEvent reads the next event from the stream, which is either a token, or a change of the role that tokens belong to.
+// Unlike TokenStream.Read, which only yields token text, it surfaces role changes within a single response,
+// such as from assistant to tool in tool-using conversations. When a token's role differs from the role of the token before it,
+// or from Role for the first token, a StreamEventRoleChange event is returned first, followed by the token itself.
+// Chunks that carry no role keep the current one.
+//
+// ReadEvent reads from TokenStream, so tokens read with one are not returned by the other, and the two should not be mixed.
+// If the stream is complete, the error will be io.EOF.
+func (r *ChatCompleteStreamResponse) ReadEvent() (StreamEvent, error) {
+	if r.pendingEvent != nil {
+		event := *r.pendingEvent
+		r.pendingEvent = nil
+		return event, nil
+	}
+	if r.currentRole == "" {
+		r.currentRole = r.Role
+	}
+
+	token, err := r.TokenStream.Read()
+	if err != nil {
+		return StreamEvent{}, err
+	}
+
+	role := r.currentRole
+	if chunkRole := r.TokenStream.lastChunk.GetResponse().GetRole(); chunkRole != "" {
+		role = chunkRole
+	}
+	event := StreamEvent{Kind: StreamEventToken, Role: role, Token: token}
+	if role == r.currentRole {
+		return event, nil
+	}
+
+	r.currentRole = role
+	r.pendingEvent = &event
+	return StreamEvent{Kind: StreamEventRoleChange, Role: role}, nil
+}
@@ -0,0 +1,230 @@
+package function_go_sdk
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parses a gateway base URL, which must be an absolute http or https URL.
+func parseBaseUrl(baseUrl string) (*url.URL, error) {
+	parsed, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", baseUrl, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: must be an absolute http or https URL", baseUrl)
+	}
+	return parsed, nil
+}
+
+// failoverHttpClient sends requests for the primary gateway to the other gateways in turn, when the previous ones cannot serve them.
+// Requests are addressed to the primary gateway by the service, and rewritten for the others; requests for other URLs are passed through.
+type failoverHttpClient struct {
+	client HttpClient
+
+	// The gateway base URLs, as configured and parsed, the primary one first.
+	baseUrls []string
+	parsed   []*url.URL
+
+	// The delay after which unary requests are also sent to the next gateway, or zero to disable hedging.
+	hedgeDelay time.Duration
+}
+
+// The result of a request sent to one of the gateways.
+type failoverAttempt struct {
+	index  int
+	res    *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// Validates the base URLs and creates a client which fails over between them, with the primary one first.
+func newFailoverHttpClient(client HttpClient, baseUrls []string, hedgeDelay time.Duration) (*failoverHttpClient, error) {
+	parsed := make([]*url.URL, len(baseUrls))
+	for i, baseUrl := range baseUrls {
+		var err error
+		if parsed[i], err = parseBaseUrl(baseUrl); err != nil {
+			return nil, err
+		}
+	}
+	if hedgeDelay < 0 {
+		return nil, fmt.Errorf("hedge delay %v must not be negative", hedgeDelay)
+	}
+
+	return &failoverHttpClient{
+		client:     client,
+		baseUrls:   baseUrls,
+		parsed:     parsed,
+		hedgeDelay: hedgeDelay,
+	}, nil
+}
+
+func (c *failoverHttpClient) Do(req *http.Request) (*http.Response, error) {
+	primary := c.parsed[0]
+	prefix := strings.TrimRight(primary.Path, "/")
+	if req.URL.Scheme != primary.Scheme || req.URL.Host != primary.Host || !strings.HasPrefix(req.URL.Path, prefix+"/") {
+		return c.client.Do(req)
+	}
+
+	// The body is buffered, so that it can be sent again. Only requests with a single message are made, so it is complete once sent.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	procedure := strings.TrimPrefix(req.URL.Path, prefix)
+
+	// Streams are not hedged, so that responses are not generated twice.
+	if c.hedgeDelay > 0 && procedure != apigatewayv1connect.APIGatewayServiceChatCompleteStreamProcedure {
+		return c.hedge(req, body, procedure)
+	}
+
+	for i := range c.parsed {
+		res, err := c.client.Do(c.request(req.Context(), req, body, procedure, i))
+		if i == len(c.parsed)-1 || !shouldFailover(req.Context(), res, err) {
+			c.served(req.Context(), i, res, err)
+			return res, err
+		}
+		discardResponse(res)
+	}
+	panic("unreachable")
+}
+
+// Sends the request to the gateways in turn, without waiting for a gateway to fail for longer than the hedge delay,
+// and returns the first response that should not be failed over, or the last one if they all should.
+func (c *failoverHttpClient) hedge(req *http.Request, body []byte, procedure string) (*http.Response, error) {
+	results := make(chan failoverAttempt, len(c.parsed))
+	cancels := make([]context.CancelFunc, len(c.parsed))
+	next, pending := 0, 0
+	send := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels[next] = cancel
+		attemptReq := c.request(ctx, req, body, procedure, next)
+		index := next
+		go func() {
+			res, err := c.client.Do(attemptReq)
+			results <- failoverAttempt{index: index, res: res, err: err, cancel: cancel}
+		}()
+		next++
+		pending++
+	}
+
+	send()
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if next < len(c.parsed) {
+				send()
+				timer.Reset(c.hedgeDelay)
+			}
+		case attempt := <-results:
+			pending--
+			if shouldFailover(req.Context(), attempt.res, attempt.err) && (next < len(c.parsed) || pending > 0) {
+				discardResponse(attempt.res)
+				attempt.cancel()
+				if next < len(c.parsed) {
+					send()
+					timer.Reset(c.hedgeDelay)
+				}
+				continue
+			}
+
+			// Requests still in flight are canceled, and their responses discarded.
+			for i, cancel := range cancels[:next] {
+				if i != attempt.index {
+					cancel()
+				}
+			}
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					discardResponse((<-results).res)
+				}
+			}(pending)
+
+			c.served(req.Context(), attempt.index, attempt.res, attempt.err)
+			if attempt.err != nil {
+				attempt.cancel()
+				return nil, attempt.err
+			}
+			attempt.res.Body = &cancelOnCloseBody{ReadCloser: attempt.res.Body, cancel: attempt.cancel}
+			return attempt.res, nil
+		}
+	}
+}
+
+// Returns a copy of req, sent to the gateway at the given index, with the buffered body.
+func (c *failoverHttpClient) request(ctx context.Context, req *http.Request, body []byte, procedure string, index int) *http.Request {
+	target := *c.parsed[index]
+	target.Path = strings.TrimRight(target.Path, "/") + procedure
+	target.RawPath = ""
+	target.RawQuery = req.URL.RawQuery
+
+	attemptReq := req.Clone(ctx)
+	attemptReq.URL = &target
+	attemptReq.Host = target.Host
+	attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+	attemptReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	attemptReq.ContentLength = int64(len(body))
+	return attemptReq
+}
+
+// Records the gateway which served a request in the call metadata, if it succeeded.
+func (c *failoverHttpClient) served(ctx context.Context, index int, res *http.Response, err error) {
+	if err != nil || res.StatusCode/100 != 2 {
+		return
+	}
+	if metadata := callMetadataFrom(ctx); metadata != nil {
+		metadata.BaseUrl = c.baseUrls[index]
+	}
+}
+
+// Returns whether a request should be sent to the next gateway: if the gateway could not be reached,
+// or if it, or a proxy in front of it, reported being unavailable. Requests whose caller gave up are not failed over.
+func shouldFailover(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Closes the body of a response which is not returned, if there is one.
+func discardResponse(res *http.Response) {
+	if res != nil {
+		res.Body.Close()
+	}
+}
+
+// cancelOnCloseBody cancels the context of a request once its response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
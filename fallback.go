@@ -0,0 +1,112 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Returns whether err indicates that a model is missing or temporarily unavailable,
+// in which case the request may succeed with a different model.
+func isModelAvailabilityError(err error) bool {
+	code := connect.CodeOf(err)
+	return code == connect.CodeNotFound || code == connect.CodeUnavailable
+}
+
+// Calls fn with the request, and then with a copy of it for each fallback model in turn, for as long as fn fails with a model availability error.
+// With each model, failed attempts are first retried according to the client's RetryPolicy, if any.
+// Before each attempt other than the first, the request is passed through the client's RewriteOnRetry hook, if any.
+// Once fn succeeds, the model that served the request is recorded in the call metadata attached to ctx, if any.
+// The error of the last attempt is returned if no model succeeded, or the error of ctx if it is done while waiting to retry.
+func tryModels[T interface {
+	modelRequest
+	proto.Message
+}, R any](ctx context.Context, c *Client, request T, fn func(T) (R, error)) (R, error) {
+	models := append([]string{request.GetModel()}, c.fallbackModels...)
+
+	var res R
+	var err error
+	attempts := 0
+	for i, model := range models {
+		base := request
+		if i > 0 {
+			base = withModel(request, model)
+		}
+
+		for try := 1; ; try++ {
+			attempt := base
+			if attempts > 0 {
+				if attempt, err = rewriteAttempt(c, base, attempts, err); err != nil {
+					return res, err
+				}
+			}
+
+			res, err = fn(attempt)
+			attempts++
+			if err == nil {
+				if metadata := callMetadataFrom(ctx); metadata != nil {
+					metadata.Model = attempt.GetModel()
+				}
+				return res, nil
+			}
+			if !c.retry.shouldRetry(try, err) {
+				break
+			}
+			if waitErr := c.retry.wait(ctx, try, err); waitErr != nil {
+				return res, waitErr
+			}
+		}
+
+		if !isModelAvailabilityError(err) {
+			break
+		}
+	}
+
+	return res, err
+}
+
+// Passes a copy of the request of a retry attempt through the client's RewriteOnRetry hook, if any, along with the error of the previous attempt.
+// A *RetryRequestTypeError is returned if the hook returns a request of a different type.
+func rewriteAttempt[T proto.Message](c *Client, request T, attempt int, lastErr error) (T, error) {
+	if c.rewriteOnRetry == nil {
+		return request, nil
+	}
+
+	request = proto.Clone(request).(T)
+	rewritten := c.rewriteOnRetry(request, attempt, lastErr)
+	if rewritten == nil {
+		return request, nil
+	}
+	typed, ok := rewritten.(T)
+	if !ok {
+		return request, &RetryRequestTypeError{
+			Expected: fmt.Sprintf("%T", request),
+			Actual:   fmt.Sprintf("%T", rewritten),
+		}
+	}
+	return typed, nil
+}
+
+// RetryRequestTypeError is returned when ClientOptions.RewriteOnRetry returns a request of a different type than the one it was given.
+type RetryRequestTypeError struct {
+	// Expected is the type of the request given to the hook, such as "*apigatewayv1.ChatCompleteRequest".
+	Expected string
+
+	// Actual is the type of the request returned by the hook.
+	Actual string
+}
+
+func (e *RetryRequestTypeError) Error() string {
+	return fmt.Sprintf("RewriteOnRetry returned a request of type %s, expected %s", e.Actual, e.Expected)
+}
+
+// Returns a copy of the request with its model replaced.
+// The original request is left untouched, as it belongs to the caller.
+func withModel[T proto.Message](request T, model string) T {
+	clone := proto.Clone(request).(T)
+	message := clone.ProtoReflect()
+	message.Set(message.Descriptor().Fields().ByName("model"), protoreflect.ValueOfString(model))
+	return clone
+}
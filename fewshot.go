@@ -0,0 +1,54 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+)
+
+// ChatExample is a few-shot example: a user message and the assistant reply that the model should imitate.
+type ChatExample struct {
+	// User is the content of the example user message.
+	User string
+
+	// Assistant is the content of the example assistant reply.
+	Assistant string
+}
+
+type fewShotExamplesKey struct{}
+
+// WithFewShotExamples returns a copy of ctx which makes chat calls use the given few-shot examples instead of ClientOptions.FewShotExamples.
+// Passing no examples disables few-shot examples for calls made with the returned context.
+func WithFewShotExamples(ctx context.Context, examples ...ChatExample) context.Context {
+	return context.WithValue(ctx, fewShotExamplesKey{}, examples)
+}
+
+// Returns the few-shot examples to use for a call made with ctx.
+func (c *Client) fewShotExamples(ctx context.Context) []ChatExample {
+	if examples, ok := ctx.Value(fewShotExamplesKey{}).([]ChatExample); ok {
+		return examples
+	}
+	return c.defaultFewShotExamples
+}
+
+// Returns a copy of messages with the examples inserted after any leading system messages, as alternating user and assistant messages.
+// If there are no examples, messages is returned as-is.
+func withFewShotExamples(messages []*apigatewayv1.ChatCompleteMessage, examples []ChatExample) []*apigatewayv1.ChatCompleteMessage {
+	if len(examples) == 0 {
+		return messages
+	}
+
+	system := 0
+	for system < len(messages) && messages[system].GetRole() == "system" {
+		system++
+	}
+
+	result := make([]*apigatewayv1.ChatCompleteMessage, 0, len(messages)+2*len(examples))
+	result = append(result, messages[:system]...)
+	for _, example := range examples {
+		result = append(result,
+			&apigatewayv1.ChatCompleteMessage{Role: "user", Content: example.User},
+			&apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: example.Assistant},
+		)
+	}
+	return append(result, messages[system:]...)
+}
@@ -0,0 +1,66 @@
+package functest_test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/functest"
+	"time"
+)
+
+func ExampleGateway() {
+	gateway := functest.NewGateway()
+	defer gateway.Close()
+	gateway.ReplyChatText("Paris")
+
+	client, _ := gateway.NewClient()
+	res, _ := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "some-model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "What is the capital of France?"}},
+	})
+	fmt.Println(res.Response.Content)
+
+	request := gateway.Requests("ChatComplete")[0].(*apigatewayv1.ChatCompleteRequest)
+	fmt.Println(request.Message[0].Content)
+	// Output:
+	// Paris
+	// What is the capital of France?
+}
+
+func ExampleGateway_stream() {
+	gateway := functest.NewGateway()
+	defer gateway.Close()
+	gateway.ReplyStream(functest.Stream{
+		Chunks: []string{"Once", " upon", " a"},
+		Delay:  time.Millisecond,
+		Err:    connect.NewError(connect.CodeInternal, errors.New("model crashed")),
+	})
+
+	client, _ := gateway.NewClient()
+	res, _ := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "some-model"})
+	tokens, err := res.TokenStream.ReadAll()
+	fmt.Printf("%q\n", tokens)
+	fmt.Println(connect.CodeOf(err))
+	// Output:
+	// ["Once" " upon" " a"]
+	// internal
+}
+
+func ExampleGateway_Fail() {
+	gateway := functest.NewGateway()
+	defer gateway.Close()
+	// The first call fails, and the retry gets the embedding, which is then reused for further calls.
+	gateway.Fail("Embed", connect.NewError(connect.CodeUnavailable, errors.New("overloaded")))
+	gateway.ReplyEmbedding([]float32{0.1, 0.2})
+
+	client, _ := gateway.NewClient(sdk.WithRetry(sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	res, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "some-model", Input: "text"})
+	fmt.Println(res.Data[0].Embedding, err)
+	fmt.Println(len(gateway.Requests("Embed")))
+	// Output:
+	// [0.1 0.2] <nil>
+	// 2
+}
@@ -0,0 +1,249 @@
+// Package functest provides an in-process fake of the Function Network API gateway, for fast and hermetic integration tests
+// of code that uses the SDK. The fake implements the same Connect service as the real gateway, and is reached through
+// in-memory connections, so a real *sdk.Client exercises its full stack, including interceptors, retries and streaming,
+// without opening any network socket.
+//
+// Unlike the sdktest server, which derives plausible replies from each request, the fake gateway replies with exactly
+// the responses, errors and stream chunks that the test programs, and records every request it receives.
+package functest
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Url is the base URL of every fake gateway. It only resolves through the HTTP client returned by Gateway.HttpClient.
+const Url = "http://functest.invalid"
+
+// ApiKey is the API key of the clients created by Gateway.NewClient. The fake gateway accepts any API key.
+const ApiKey = "functest-key"
+
+// Stream is a scripted chat completion stream.
+// As the real gateway does, the fake first sends a chunk with only the role, then a chunk per element of Chunks.
+type Stream struct {
+	// Role is the role of the response message. If unset, it defaults to "assistant".
+	Role string
+
+	// Chunks are the contents of the chunks, sent in order.
+	Chunks []string
+
+	// Delay is the time to wait before sending each chunk after the role, to simulate generation speed.
+	// The wait ends early if the client cancels the stream.
+	Delay time.Duration
+
+	// Err, if set, ends the stream with this error once all chunks were sent, to simulate a stream that fails partway.
+	// It should be a *connect.Error, to control the code the client sees; other errors are reported as connect.CodeUnknown.
+	Err error
+}
+
+// reply is a programmed reply to a call: a response message, a stream, or an error.
+type reply struct {
+	msg    proto.Message
+	stream *Stream
+	err    error
+}
+
+// Gateway is an in-process fake API gateway. Create one with NewGateway, program its replies, and close it once done.
+//
+// Replies are programmed per method, such as with ReplyChat or Fail, and are used in the order they were programmed,
+// one per call; the last reply of a method is reused for any further calls. Calls to a method without replies fail
+// with connect.CodeUnimplemented. Programming replies while calls are in flight is safe.
+type Gateway struct {
+	listener *memoryListener
+	server   *http.Server
+	client   *http.Client
+
+	mu       sync.Mutex
+	replies  map[string][]reply
+	requests map[string][]proto.Message
+}
+
+// NewGateway starts a fake gateway, with no replies programmed.
+func NewGateway() *Gateway {
+	g := &Gateway{
+		listener: newMemoryListener(),
+		replies:  map[string][]reply{},
+		requests: map[string][]proto.Message{},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(&gatewayHandler{gateway: g}))
+	g.server = &http.Server{Handler: mux}
+	go g.server.Serve(g.listener)
+
+	g.client = &http.Client{Transport: &http.Transport{DialContext: g.listener.dial}}
+	return g
+}
+
+// Close shuts the gateway down, interrupting any calls in flight.
+func (g *Gateway) Close() {
+	g.server.Close()
+	g.client.CloseIdleConnections()
+}
+
+// HttpClient returns an HTTP client which sends requests for Url to the gateway, to use as sdk.ClientOptions.HttpClient.
+func (g *Gateway) HttpClient() *http.Client {
+	return g.client
+}
+
+// NewClient creates a client of the gateway, with ApiKey, further configured by opts.
+// Options which replace the base URL or the HTTP client make the client talk to another gateway.
+func (g *Gateway) NewClient(opts ...sdk.ClientOption) (*sdk.Client, error) {
+	return sdk.New(ApiKey, append([]sdk.ClientOption{sdk.WithBaseUrl(Url), sdk.WithHttpClient(g.client)}, opts...)...)
+}
+
+// Programs a reply to the given method.
+func (g *Gateway) add(method string, r reply) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.replies[method] = append(g.replies[method], r)
+}
+
+// ReplyChat programs a ChatComplete reply.
+func (g *Gateway) ReplyChat(res *apigatewayv1.ChatCompleteResponse) {
+	g.add("ChatComplete", reply{msg: res})
+}
+
+// ReplyChatText programs a ChatComplete reply with an assistant message with the given content.
+// The token count is the number of characters of the content, divided by 4 and rounded up, a common approximation.
+func (g *Gateway) ReplyChatText(content string) {
+	g.ReplyChat(&apigatewayv1.ChatCompleteResponse{
+		Response:   &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: content},
+		TokenCount: int32((len(content) + 3) / 4),
+	})
+}
+
+// ReplyStream programs a ChatCompleteStream reply.
+func (g *Gateway) ReplyStream(stream Stream) {
+	g.add("ChatCompleteStream", reply{stream: &stream})
+}
+
+// ReplyEmbed programs an Embed reply.
+func (g *Gateway) ReplyEmbed(res *apigatewayv1.EmbedResponse) {
+	g.add("Embed", reply{msg: res})
+}
+
+// ReplyEmbedding programs an Embed reply with the given embedding.
+func (g *Gateway) ReplyEmbedding(embedding []float32) {
+	g.ReplyEmbed(&apigatewayv1.EmbedResponse{
+		Object: "list",
+		Data:   []*apigatewayv1.EmbedResponse_Data{{Object: "embedding", Embedding: embedding}},
+	})
+}
+
+// ReplyImage programs a TextToImage reply.
+func (g *Gateway) ReplyImage(res *apigatewayv1.TextToImageResponse) {
+	g.add("TextToImage", reply{msg: res})
+}
+
+// ReplyTranscribe programs a Transcribe reply.
+func (g *Gateway) ReplyTranscribe(res *apigatewayv1.TranscribeResponse) {
+	g.add("Transcribe", reply{msg: res})
+}
+
+// Fail programs a reply to the given method, such as "ChatComplete", which fails with err.
+// It should be a *connect.Error, to control the code the client sees; other errors are reported as connect.CodeUnknown.
+func (g *Gateway) Fail(method string, err error) {
+	g.add(method, reply{err: err})
+}
+
+// Requests returns the requests received for the given method, such as "ChatComplete", in order, including retries.
+func (g *Gateway) Requests(method string) []proto.Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]proto.Message(nil), g.requests[method]...)
+}
+
+// Records a request for the given method, and returns the reply to it.
+func (g *Gateway) next(method string, request proto.Message) (reply, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.requests[method] = append(g.requests[method], request)
+	replies := g.replies[method]
+	if len(replies) == 0 {
+		return reply{}, connect.NewError(connect.CodeUnimplemented, fmt.Errorf("functest: no reply is programmed for %s", method))
+	}
+	if len(replies) > 1 {
+		g.replies[method] = replies[1:]
+	}
+	return replies[0], nil
+}
+
+// gatewayHandler serves the programmed replies of a gateway.
+type gatewayHandler struct {
+	gateway *Gateway
+}
+
+var _ apigatewayv1connect.APIGatewayServiceHandler = (*gatewayHandler)(nil)
+
+// Answers a unary call with the next reply programmed for the method.
+func respond[Req any, Res any](g *Gateway, method string, req *connect.Request[Req]) (*connect.Response[Res], error) {
+	r, err := g.next(method, any(req.Msg).(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return connect.NewResponse(any(r.msg).(*Res)), nil
+}
+
+func (h *gatewayHandler) ChatComplete(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+	return respond[apigatewayv1.ChatCompleteRequest, apigatewayv1.ChatCompleteResponse](h.gateway, "ChatComplete", req)
+}
+
+func (h *gatewayHandler) ChatCompleteStream(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+	r, err := h.gateway.next("ChatCompleteStream", req.Msg)
+	if err != nil {
+		return err
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	role := r.stream.Role
+	if role == "" {
+		role = "assistant"
+	}
+	if err := stream.Send(&apigatewayv1.ChatCompleteStreamResponse{Response: &apigatewayv1.ChatCompleteMessage{Role: role}}); err != nil {
+		return err
+	}
+	for _, chunk := range r.stream.Chunks {
+		if r.stream.Delay > 0 {
+			select {
+			case <-time.After(r.stream.Delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := stream.Send(&apigatewayv1.ChatCompleteStreamResponse{
+			Response: &apigatewayv1.ChatCompleteMessage{Role: role, Content: chunk},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return r.stream.Err
+}
+
+func (h *gatewayHandler) Embed(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+	return respond[apigatewayv1.EmbedRequest, apigatewayv1.EmbedResponse](h.gateway, "Embed", req)
+}
+
+func (h *gatewayHandler) TextToImage(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+	return respond[apigatewayv1.TextToImageRequest, apigatewayv1.TextToImageResponse](h.gateway, "TextToImage", req)
+}
+
+func (h *gatewayHandler) Transcribe(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+	return respond[apigatewayv1.TranscribeRequest, apigatewayv1.TranscribeResponse](h.gateway, "Transcribe", req)
+}
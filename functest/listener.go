@@ -0,0 +1,70 @@
+package functest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+// memoryListener is a net.Listener whose connections are in-memory pipes, created by dial, so that a server can be reached
+// without opening a network socket.
+type memoryListener struct {
+	conns chan net.Conn
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newMemoryListener() *memoryListener {
+	return &memoryListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *memoryListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *memoryListener) Addr() net.Addr {
+	return memoryAddr{}
+}
+
+// Connects to the listener, and matches the signature of http.Transport.DialContext.
+func (l *memoryListener) dial(ctx context.Context, network string, address string) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		client.Close()
+		server.Close()
+		return nil, errors.New("functest: the gateway is closed")
+	case <-ctx.Done():
+		client.Close()
+		server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// memoryAddr is the address of a memoryListener.
+type memoryAddr struct{}
+
+func (memoryAddr) Network() string {
+	return "memory"
+}
+
+func (memoryAddr) String() string {
+	return "functest"
+}
@@ -3,8 +3,34 @@ module github.com/fxnlabs/function-go-sdk
 go 1.23.2
 
 require (
-	buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go v1.17.0-20241119193538-3b4c29925751.1 // indirect
-	buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go v1.34.2-20241119193538-3b4c29925751.2 // indirect
-	connectrpc.com/connect v1.17.0 // indirect
-	google.golang.org/protobuf v1.34.2 // indirect
+	buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go v1.17.0-20241119193538-3b4c29925751.1
+	buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go v1.34.2-20241119193538-3b4c29925751.2
+	connectrpc.com/connect v1.17.0
+	golang.org/x/time v0.9.0
+)
+
+require google.golang.org/protobuf v1.34.2
+
+require (
+	github.com/prometheus/client_golang v1.20.5
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/metric v1.31.0
+	go.opentelemetry.io/otel/sdk v1.31.0
+	go.opentelemetry.io/otel/sdk/metric v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+	golang.org/x/text v0.21.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 )
@@ -0,0 +1,67 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"time"
+)
+
+// CallInfo describes a call, as reported to the OnRequest, OnResponse and OnError hooks of ClientOptions.
+// A call is reported once, however many attempts it took, including retries and fallbacks.
+type CallInfo struct {
+	// Method is the client method of the call, such as "ChatComplete" or "ChatCompleteStream".
+	Method string
+
+	// Model is the requested model when the call starts, and the model of the latest attempt when it ends,
+	// which may differ if a fallback model was used.
+	Model string
+
+	// Stream is whether the call is a stream.
+	Stream bool
+
+	// Duration is the time from the start of the call to its end. For streams, it ends once the stream is read to its end,
+	// fails, or is closed. It is zero when the call starts.
+	Duration time.Duration
+
+	// Code is the status code of a failed call, or zero for calls that have not failed.
+	Code connect.Code
+
+	// Err is the error of a failed call, as returned to the caller.
+	Err error
+
+	// Usage is the token usage of a successful call, as reported to OnUsage.
+	// It is zero if the response does not report usage, or for streams closed before they were read to their end.
+	Usage TokenUsage
+}
+
+// Reports the start of a call to the OnRequest hook, and returns a function to report its end to the OnResponse or OnError hook.
+// If the client has no hooks, nothing is reported.
+func (c *Client) startCall(ctx context.Context, method string, model string, stream bool) func(model string, usage TokenUsage, err error) {
+	if c.onRequest == nil && c.onResponse == nil && c.onError == nil {
+		return func(string, TokenUsage, error) {}
+	}
+
+	info := CallInfo{Method: method, Model: model, Stream: stream}
+	if c.onRequest != nil {
+		c.onRequest(ctx, info)
+	}
+
+	startedAt := time.Now()
+	return func(model string, usage TokenUsage, err error) {
+		info.Model = model
+		info.Duration = time.Since(startedAt)
+		if err != nil {
+			info.Code = connect.CodeOf(err)
+			info.Err = err
+			if c.onError != nil {
+				c.onError(ctx, info)
+			}
+			return
+		}
+
+		info.Usage = usage
+		if c.onResponse != nil {
+			c.onResponse(ctx, info)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package function_go_sdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+// UnexpectedStatusError is returned when an HTTP request made outside of the API gateway, such as an image download,
+// completed with a non-2xx status code.
+type UnexpectedStatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Status is the HTTP status line of the response, such as "404 Not Found".
+	Status string
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %s", e.Status)
+}
+
+// FetchImage requests an image URL, such as one returned by TextToImage, and returns the raw HTTP response with its body unread.
+// This gives access to headers such as Content-Type, Content-Length and Cache-Control, and lets the caller stream the body however they like.
+// The request is made with the client's HTTP client, but without the API key, as image URLs are not served by the API gateway.
+//
+// If the response has a non-2xx status code, its body is closed and an *UnexpectedStatusError is returned.
+// Otherwise, the caller must close the response body once done with it.
+func (c *Client) FetchImage(ctx context.Context, url string) (*http.Response, error) {
+	if c.lifecycle.ctx.Err() != nil {
+		return nil, c.methodError("FetchImage", ClientCanceledError)
+	}
+
+	ctx, release := c.lifecycle.derive(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		release()
+		return nil, c.methodError("FetchImage", err)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		release()
+		if c.lifecycle.ctx.Err() != nil {
+			return nil, c.methodError("FetchImage", ClientCanceledError)
+		}
+		return nil, c.methodError("FetchImage", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		release()
+		return nil, c.methodError("FetchImage", &UnexpectedStatusError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+		})
+	}
+
+	// The request context must stay alive until the caller is done reading the body.
+	res.Body = &releasingReadCloser{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// releasingReadCloser calls release once it is closed.
+type releasingReadCloser struct {
+	io.ReadCloser
+
+	release context.CancelFunc
+}
+
+func (r *releasingReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+// ImageFormat is an image encoding that SaveImage can convert downloaded images to.
+type ImageFormat string
+
+const (
+	// ImageFormatPng is the lossless PNG format.
+	ImageFormatPng ImageFormat = "png"
+
+	// ImageFormatJpeg is the lossy JPEG format. Its quality is set by SaveImageOptions.JpegQuality.
+	ImageFormatJpeg ImageFormat = "jpeg"
+
+	// ImageFormatGif is the GIF format, which is limited to 256 colors, so images are quantized when converted to it.
+	ImageFormatGif ImageFormat = "gif"
+)
+
+// UnsupportedImageFormatError is returned by SaveImage when the requested format is not one of the supported ImageFormat values.
+type UnsupportedImageFormatError struct {
+	Format ImageFormat
+}
+
+func (e *UnsupportedImageFormatError) Error() string {
+	return fmt.Sprintf("unsupported image format %q", e.Format)
+}
+
+// SaveImageOptions configures SaveImage.
+type SaveImageOptions struct {
+	// Format is the format to convert the image to. If empty, the image is written exactly as it was downloaded.
+	Format ImageFormat
+
+	// JpegQuality is the quality of JPEG images, from 1 to 100, where higher is better. If zero, jpeg.DefaultQuality is used.
+	// It is ignored for other formats, as they are lossless.
+	JpegQuality int
+}
+
+// SaveImage downloads an image URL, such as one returned by TextToImage, and writes it to w, converting it to options.Format if set,
+// so that downstream pipelines receive images in a consistent format. The image is downloaded as with FetchImage.
+//
+// Images are decoded with the standard library, so only PNG, JPEG and GIF images can be converted. If an image is already in the
+// requested format, it is written as-is rather than re-encoded, so JPEG images do not lose quality; JpegQuality only applies to
+// images converted from another format. Only the first frame of animated GIF images is kept when converting them.
+// An *UnsupportedImageFormatError is returned, before downloading anything, if the requested format is not supported.
+func (c *Client) SaveImage(ctx context.Context, url string, w io.Writer, options *SaveImageOptions) error {
+	if options == nil {
+		options = &SaveImageOptions{}
+	}
+	switch options.Format {
+	case "", ImageFormatPng, ImageFormatJpeg, ImageFormatGif:
+	default:
+		return c.methodError("SaveImage", &UnsupportedImageFormatError{Format: options.Format})
+	}
+
+	res, err := c.FetchImage(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if options.Format == "" {
+		if _, err := io.Copy(w, res.Body); err != nil {
+			return c.methodError("SaveImage", err)
+		}
+		return nil
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return c.methodError("SaveImage", err)
+	}
+	if err := encodeImage(w, data, options); err != nil {
+		return c.methodError("SaveImage", err)
+	}
+	return nil
+}
+
+// Writes the encoded image data to w in options.Format, re-encoding it only if it is in another format.
+func encodeImage(w io.Writer, data []byte, options *SaveImageOptions) error {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	if ImageFormat(format) == options.Format {
+		_, err := w.Write(data)
+		return err
+	}
+
+	switch options.Format {
+	case ImageFormatJpeg:
+		quality := options.JpegQuality
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case ImageFormatGif:
+		return gif.Encode(w, img, nil)
+	default:
+		return png.Encode(w, img)
+	}
+}
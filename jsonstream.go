@@ -0,0 +1,272 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JsonField is a top-level field of a JSON object, read from a JsonFieldStream.
+type JsonField struct {
+	// Name is the field name.
+	Name string
+
+	// Value is the complete JSON value of the field, which can be decoded with json.Unmarshal.
+	Value json.RawMessage
+}
+
+// MalformedJsonError is returned by JsonFieldStream when the streamed response is not a valid JSON object.
+type MalformedJsonError struct {
+	// Offset is the byte offset in the response at which the problem was detected.
+	Offset int
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *MalformedJsonError) Error() string {
+	return fmt.Sprintf("malformed JSON at offset %d: %s", e.Offset, e.Reason)
+}
+
+// The position of a JsonFieldStream within the JSON object.
+type jsonScanState int
+
+const (
+	jsonBeforeObject jsonScanState = iota
+	jsonBeforeFirstKey
+	jsonBeforeKey
+	jsonInKey
+	jsonAfterKey
+	jsonBeforeValue
+	jsonInValue
+	jsonAfterValue
+	jsonDone
+)
+
+// JsonFieldStream incrementally parses a JSON object from a token stream, such as a chat completion in JSON mode,
+// and yields each top-level field as soon as its value is complete.
+// Create one with StreamJsonFields.
+type JsonFieldStream struct {
+	stream *ResponseStream[apigatewayv1.ChatCompleteStreamResponse, string]
+
+	// The text read so far, and the offset up to which it was scanned.
+	buffer []byte
+	offset int
+
+	state jsonScanState
+
+	// The start of the current key or value in buffer.
+	start int
+	name  string
+
+	// Nesting depth and string state of the current value.
+	depth    int
+	inString bool
+	escaped  bool
+
+	// Fields ready to be returned by Read.
+	queue []JsonField
+
+	// The error that ended parsing, returned once the queue is drained.
+	err error
+}
+
+// StreamJsonFields parses the token stream of a chat completion stream as a JSON object, and returns a stream of its top-level fields,
+// so that structured output, such as form fields, can be rendered progressively.
+// Any text before the opening brace or after the closing brace of the object, such as a Markdown code fence, is ignored.
+//
+// Only complete fields are yielded: a field is returned by Read once its whole value has been streamed, so an object or array value
+// is returned at once, rather than element by element. Each value is validated on its own as it completes, but the object as a whole
+// is only known to be well-formed at the end of the stream. Once it is complete, Read yields io.EOF. If the stream ends before the
+// object is closed, or the text is not valid JSON, Read yields a *MalformedJsonError, after any fields that were completed before.
+// Fields are yielded in the order they appear, and duplicate names are yielded each time they appear.
+func StreamJsonFields(res *ChatCompleteStreamResponse) *JsonFieldStream {
+	return &JsonFieldStream{
+		stream: res.TokenStream,
+	}
+}
+
+// Read returns the next complete top-level field.
+// If the object is complete, the error will be io.EOF.
+func (s *JsonFieldStream) Read() (JsonField, error) {
+	for len(s.queue) == 0 {
+		if s.err != nil {
+			return JsonField{}, s.err
+		}
+		s.fill()
+	}
+
+	field := s.queue[0]
+	s.queue = s.queue[1:]
+	return field, nil
+}
+
+// Reads a token from the underlying stream and scans it.
+func (s *JsonFieldStream) fill() {
+	token, err := s.stream.Read()
+	if errors.Is(err, io.EOF) {
+		if s.state != jsonDone {
+			s.err = &MalformedJsonError{Offset: len(s.buffer), Reason: "the response ended before the object was complete"}
+			return
+		}
+		s.err = io.EOF
+		return
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	s.buffer = append(s.buffer, token...)
+	for s.err == nil && s.offset < len(s.buffer) {
+		s.scan(s.buffer[s.offset])
+		s.offset++
+	}
+}
+
+// Scans a single byte at the current offset.
+func (s *JsonFieldStream) scan(c byte) {
+	switch s.state {
+	case jsonBeforeObject:
+		if c == '{' {
+			s.state = jsonBeforeFirstKey
+		}
+
+	case jsonBeforeFirstKey, jsonBeforeKey:
+		switch {
+		case isJsonSpace(c):
+		case c == '"':
+			s.state = jsonInKey
+			s.start = s.offset
+		case c == '}' && s.state == jsonBeforeFirstKey:
+			s.state = jsonDone
+		default:
+			s.fail("expected a field name")
+		}
+
+	case jsonInKey:
+		if s.scanString(c) {
+			return
+		}
+		if err := json.Unmarshal(s.buffer[s.start:s.offset+1], &s.name); err != nil {
+			s.fail("invalid field name")
+			return
+		}
+		s.state = jsonAfterKey
+
+	case jsonAfterKey:
+		switch {
+		case isJsonSpace(c):
+		case c == ':':
+			s.state = jsonBeforeValue
+		default:
+			s.fail("expected a colon after the field name")
+		}
+
+	case jsonBeforeValue:
+		if isJsonSpace(c) {
+			return
+		}
+		s.state = jsonInValue
+		s.start = s.offset
+		s.scanValue(c)
+
+	case jsonInValue:
+		s.scanValue(c)
+
+	case jsonAfterValue:
+		switch {
+		case isJsonSpace(c):
+		case c == ',':
+			s.state = jsonBeforeKey
+		case c == '}':
+			s.state = jsonDone
+		default:
+			s.fail("expected a comma or closing brace after the field value")
+		}
+	}
+}
+
+// Scans a byte within a string, after its opening quote, and returns whether the string continues after it.
+func (s *JsonFieldStream) scanString(c byte) bool {
+	switch {
+	case s.escaped:
+		s.escaped = false
+	case c == '\\':
+		s.escaped = true
+	case c == '"':
+		return false
+	}
+	return true
+}
+
+// Scans a byte within a field value, and queues the field once the value is complete.
+// Scalar values other than strings are only complete once the byte after them is scanned, which is then scanned again after the value.
+func (s *JsonFieldStream) scanValue(c byte) {
+	if s.inString {
+		s.inString = s.scanString(c)
+		if !s.inString && s.depth == 0 {
+			s.complete(s.offset + 1)
+		}
+		return
+	}
+
+	switch c {
+	case '"':
+		s.inString = true
+	case '{', '[':
+		s.depth++
+	case '}', ']':
+		if s.depth == 0 {
+			s.complete(s.offset)
+			s.offset--
+			return
+		}
+		s.depth--
+		if s.depth == 0 {
+			s.complete(s.offset + 1)
+		}
+	case ',':
+		if s.depth == 0 {
+			s.complete(s.offset)
+			s.offset--
+		}
+	default:
+		if s.depth == 0 && isJsonSpace(c) {
+			s.complete(s.offset)
+		}
+	}
+}
+
+// Queues the field whose value ends at end, after validating the value.
+func (s *JsonFieldStream) complete(end int) {
+	value := s.buffer[s.start:end]
+	s.state = jsonAfterValue
+	if !json.Valid(value) {
+		s.fail(fmt.Sprintf("invalid value for field %q", s.name))
+		return
+	}
+
+	s.queue = append(s.queue, JsonField{Name: s.name, Value: json.RawMessage(value)})
+}
+
+// Ends parsing with a *MalformedJsonError at the current offset.
+func (s *JsonFieldStream) fail(reason string) {
+	s.err = &MalformedJsonError{Offset: s.offset, Reason: reason}
+}
+
+func isJsonSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// Close closes the underlying stream.
+// Any fields that have not been read yet are discarded, and subsequent calls to Read will yield io.EOF.
+func (s *JsonFieldStream) Close() error {
+	s.queue = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	return s.stream.Close()
+}
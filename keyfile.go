@@ -0,0 +1,81 @@
+package function_go_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EmptyApiKeyFileError is the underlying error of an *ApiKeyFileError when the API key file holds no key.
+var EmptyApiKeyFileError = errors.New("the API key file is empty")
+
+// ApiKeyFileError is returned by NewClient when the API key could not be read from ClientOptions.ApiKeyFile.
+type ApiKeyFileError struct {
+	// Path is the path of the API key file.
+	Path string
+
+	// Err is the underlying error, such as EmptyApiKeyFileError or an error from reading the file.
+	Err error
+}
+
+func (e *ApiKeyFileError) Error() string {
+	return fmt.Sprintf("could not read API key file %q: %v", e.Path, e.Err)
+}
+
+func (e *ApiKeyFileError) Unwrap() error {
+	return e.Err
+}
+
+// Reads an API key from a file, without trailing line breaks.
+func readApiKeyFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", &ApiKeyFileError{Path: path, Err: err}
+	}
+
+	key := strings.TrimRight(string(contents), "\r\n")
+	if key == "" {
+		return "", &ApiKeyFileError{Path: path, Err: EmptyApiKeyFileError}
+	}
+	return key, nil
+}
+
+// reloadingApiKey holds an API key read from a file, and re-reads the file when the key is used after the reload interval has passed.
+// This avoids a background goroutine, at the cost of reloads only happening when requests are made.
+type reloadingApiKey struct {
+	path     string
+	interval time.Duration
+
+	key atomic.Pointer[string]
+
+	// The time after which the file should be read again, in Unix nanoseconds.
+	nextReload atomic.Int64
+}
+
+func newReloadingApiKey(path string, key string, interval time.Duration) *reloadingApiKey {
+	k := &reloadingApiKey{
+		path:     path,
+		interval: interval,
+	}
+	k.key.Store(&key)
+	k.nextReload.Store(time.Now().Add(interval).UnixNano())
+	return k
+}
+
+// Token returns the current key, after re-reading the file if the reload interval has passed.
+// If the file cannot be read, or holds no key, the previous key is kept, as the file may be in the middle of being replaced.
+func (k *reloadingApiKey) Token(ctx context.Context) (string, error) {
+	now := time.Now()
+	next := k.nextReload.Load()
+	// Only one caller reloads the key, while concurrent callers keep using the previous key.
+	if now.UnixNano() >= next && k.nextReload.CompareAndSwap(next, now.Add(k.interval).UnixNano()) {
+		if key, err := readApiKeyFile(k.path); err == nil {
+			k.key.Store(&key)
+		}
+	}
+	return *k.key.Load(), nil
+}
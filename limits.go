@@ -0,0 +1,146 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// ModelLimits describes the request limits of a model.
+// Zero values mean that the corresponding limit is unknown, and is not checked.
+type ModelLimits struct {
+	// MaxInputTokens is the maximum number of tokens in a request, also known as the context window.
+	MaxInputTokens int
+
+	// MaxOutputTokens is the maximum number of tokens the model generates in a response.
+	MaxOutputTokens int
+
+	// MaxRequestBytes is the maximum serialized size of a request, in bytes.
+	MaxRequestBytes int
+
+	// MaxMessages is the maximum number of messages in a chat request.
+	MaxMessages int
+}
+
+// PayloadTooLargeError is returned by CheckRequestSize when a request exceeds the size or token limit of its model.
+type PayloadTooLargeError struct {
+	// Model is the model the request was checked against.
+	Model string
+
+	// Bytes is the serialized size of the request, and MaxBytes is the model's limit, or 0 if it is unknown.
+	Bytes    int
+	MaxBytes int
+
+	// Tokens is the estimated token count of the request, and MaxTokens is the model's limit, or 0 if it is unknown.
+	Tokens    int
+	MaxTokens int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	if e.MaxBytes > 0 && e.Bytes > e.MaxBytes {
+		return fmt.Sprintf("request is %d bytes, which exceeds the %d byte limit of model %q", e.Bytes, e.MaxBytes, e.Model)
+	}
+	return fmt.Sprintf("request is about %d tokens, which exceeds the %d token limit of model %q", e.Tokens, e.MaxTokens, e.Model)
+}
+
+// TooManyMessagesError is returned by CheckRequestSize when a chat request has more messages than its model allows.
+type TooManyMessagesError struct {
+	// Model is the model the request was checked against.
+	Model string
+
+	// Messages is the number of messages in the request.
+	Messages int
+
+	// MaxMessages is the model's limit.
+	MaxMessages int
+}
+
+func (e *TooManyMessagesError) Error() string {
+	return fmt.Sprintf("request has %d messages, which exceeds the %d message limit of model %q", e.Messages, e.MaxMessages, e.Model)
+}
+
+// ContextWindow returns the maximum number of input and output tokens of a model, so that applications can build their own guardrails,
+// such as trimming a conversation to fit. Either value is 0 if the corresponding limit is unknown.
+//
+// The gateway does not publish model metadata, so limits are looked up in ClientOptions.ModelLimits, without making any network calls.
+// A *ModelNotFoundError is returned if the model has no configured limits.
+func (c *Client) ContextWindow(ctx context.Context, model string) (maxInput int, maxOutput int, err error) {
+	limits, ok := c.modelLimits[model]
+	if !ok {
+		return 0, 0, c.methodError("ContextWindow", &ModelNotFoundError{Model: model})
+	}
+
+	return limits.MaxInputTokens, limits.MaxOutputTokens, nil
+}
+
+// CheckRequestSize checks a request against the limits of its model, as configured in ClientOptions.ModelLimits,
+// without making any network calls. This lets applications validate user input immediately and before incurring any cost.
+//
+// The request is one of the API gateway request messages, such as *apigatewayv1.ChatCompleteRequest.
+// Its serialized size is compared against MaxRequestBytes, its message count against MaxMessages,
+// and its estimated token count against MaxInputTokens. Token counts are a rough estimate of one token per four characters,
+// so limits close to the estimate may still be exceeded in practice.
+//
+// A *PayloadTooLargeError or *TooManyMessagesError is returned if a limit is exceeded.
+// If the request's model has no configured limits, nil is returned.
+func (c *Client) CheckRequestSize(ctx context.Context, request proto.Message) error {
+	if request == nil {
+		return c.methodError("CheckRequestSize", NilRequestError)
+	}
+
+	model := RequestModel(request)
+	limits, ok := c.modelLimits[model]
+	if !ok {
+		return nil
+	}
+
+	messages, text := requestContent(request)
+	if limits.MaxMessages > 0 && messages > limits.MaxMessages {
+		return c.methodError("CheckRequestSize", &TooManyMessagesError{
+			Model:       model,
+			Messages:    messages,
+			MaxMessages: limits.MaxMessages,
+		})
+	}
+
+	size := proto.Size(request)
+	tokens := 0
+	for _, content := range text {
+		tokens += EstimateTokens(content)
+	}
+	if (limits.MaxRequestBytes > 0 && size > limits.MaxRequestBytes) || (limits.MaxInputTokens > 0 && tokens > limits.MaxInputTokens) {
+		return c.methodError("CheckRequestSize", &PayloadTooLargeError{
+			Model:     model,
+			Bytes:     size,
+			MaxBytes:  limits.MaxRequestBytes,
+			Tokens:    tokens,
+			MaxTokens: limits.MaxInputTokens,
+		})
+	}
+
+	return nil
+}
+
+// Returns the number of chat messages in a request, and the text in it that counts towards its token count.
+func requestContent(request proto.Message) (int, []string) {
+	var messages []*apigatewayv1.ChatCompleteMessage
+	switch request := request.(type) {
+	case *apigatewayv1.ChatCompleteRequest:
+		messages = request.Message
+	case *apigatewayv1.ChatCompleteStreamRequest:
+		messages = request.Message
+	case *apigatewayv1.EmbedRequest:
+		return 0, []string{request.Input}
+	case *apigatewayv1.TextToImageRequest:
+		return 0, []string{request.Prompt}
+	default:
+		return 0, nil
+	}
+
+	text := make([]string, len(messages))
+	for i, message := range messages {
+		text[i] = message.GetContent()
+	}
+	return len(messages), text
+}
@@ -0,0 +1,204 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Headers whose values are replaced with redactedValue in logs, as they hold credentials.
+var redactedHeaders = []string{"X-Api-Key", "Authorization", "Proxy-Authorization"}
+
+// The value logged in place of credentials.
+const redactedValue = "[REDACTED]"
+
+// Returns a copy of header, with the values of credential headers redacted.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range redactedHeaders {
+		if values := redacted.Values(key); len(values) > 0 {
+			redacted[key] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// loggingInterceptor logs a summary of every request with a structured logger: the request at debug level,
+// then the response at info level, or the error at error level.
+// The contents of requests and responses are only logged if logContent is set; otherwise, only the size of prompts is.
+type loggingInterceptor struct {
+	// The logger to log to. If nil, nothing is logged.
+	logger *slog.Logger
+
+	logContent bool
+}
+
+// Logs a request about to be sent.
+func (i *loggingInterceptor) logRequest(ctx context.Context, method string, header http.Header, msg any) {
+	attrs := []any{
+		slog.String("method", method),
+		slog.String("model", RequestModel(msg)),
+		slog.Any("headers", redactHeader(header)),
+	}
+	if i.logContent {
+		attrs = append(attrs, slog.String("request", messageJson(msg)))
+	} else if chars, ok := promptChars(msg); ok {
+		attrs = append(attrs, slog.Int("prompt_chars", chars))
+	}
+	i.logger.DebugContext(ctx, "function request", attrs...)
+}
+
+// Logs the outcome of a request. content is the response content to log if logContent is set, if not empty.
+func (i *loggingInterceptor) logResponse(ctx context.Context, method string, model string, start time.Time, usage TokenUsage, content string, err error) {
+	attrs := []any{
+		slog.String("method", method),
+		slog.String("model", model),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("code", connect.CodeOf(err).String()), slog.String("error", err.Error()))
+		i.logger.ErrorContext(ctx, "function request failed", attrs...)
+		return
+	}
+
+	if usage.PromptTokens > 0 {
+		attrs = append(attrs, slog.Int("prompt_tokens", usage.PromptTokens))
+	}
+	if usage.CompletionTokens > 0 {
+		attrs = append(attrs, slog.Int("completion_tokens", usage.CompletionTokens))
+	}
+	if i.logContent && content != "" {
+		attrs = append(attrs, slog.String("response", content))
+	}
+	i.logger.InfoContext(ctx, "function response", attrs...)
+}
+
+// Returns the protobuf JSON encoding of a message, or an empty string if it is not a protobuf message.
+func messageJson(msg any) string {
+	message, ok := msg.(proto.Message)
+	if !ok {
+		return ""
+	}
+	encoded, err := protojson.Marshal(message)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func (i *loggingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if i.logger == nil {
+			return next(ctx, req)
+		}
+
+		method := path.Base(req.Spec().Procedure)
+		start := time.Now()
+		i.logRequest(ctx, method, req.Header(), req.Any())
+
+		res, err := next(ctx, req)
+		var usage TokenUsage
+		var content string
+		if err == nil {
+			usage, _ = responseUsage(res.Any())
+			content = messageJson(res.Any())
+		}
+		i.logResponse(ctx, method, RequestModel(req.Any()), start, usage, content, err)
+		return res, err
+	}
+}
+
+func (i *loggingInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if i.logger == nil {
+			return conn
+		}
+
+		return &loggingConn{
+			StreamingClientConn: conn,
+			ctx:                 ctx,
+			interceptor:         i,
+			method:              path.Base(spec.Procedure),
+		}
+	}
+}
+
+func (i *loggingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// loggingConn logs the request of a stream when it is sent, and a summary of the stream once it ends,
+// either because it was read to its end, it failed, or it was closed.
+type loggingConn struct {
+	connect.StreamingClientConn
+
+	ctx         context.Context
+	interceptor *loggingInterceptor
+	method      string
+
+	start time.Time
+	model string
+
+	// The number of tokens received, and their content if it is logged.
+	tokens  int
+	content strings.Builder
+	ended   bool
+}
+
+func (c *loggingConn) Send(msg any) error {
+	c.start = time.Now()
+	c.model = RequestModel(msg)
+	c.interceptor.logRequest(c.ctx, c.method, c.RequestHeader(), msg)
+
+	err := c.StreamingClientConn.Send(msg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.end(err)
+	}
+	return err
+}
+
+func (c *loggingConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			c.end(nil)
+		} else {
+			c.end(err)
+		}
+		return err
+	}
+
+	if chunk, ok := msg.(*apigatewayv1.ChatCompleteStreamResponse); ok && chunk.GetResponse().GetContent() != "" {
+		c.tokens++
+		if c.interceptor.logContent {
+			c.content.WriteString(chunk.Response.Content)
+		}
+	}
+	return nil
+}
+
+func (c *loggingConn) CloseResponse() error {
+	c.end(nil)
+	return c.StreamingClientConn.CloseResponse()
+}
+
+// Logs the summary of the stream, once.
+func (c *loggingConn) end(err error) {
+	if c.ended || c.start.IsZero() {
+		return
+	}
+	c.ended = true
+
+	usage := TokenUsage{CompletionTokens: c.tokens, TotalTokens: c.tokens}
+	c.interceptor.logResponse(c.ctx, c.method, c.model, c.start, usage, c.content.String(), err)
+}
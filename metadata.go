@@ -0,0 +1,43 @@
+package function_go_sdk
+
+import (
+	"context"
+	"net/http"
+)
+
+// CallMetadata holds information about how a call was served, which is not part of the response message itself.
+// To receive it, attach one to the call's context using WithCallMetadata; it is filled in once the call succeeds.
+type CallMetadata struct {
+	// Model is the model that served the request.
+	// This may differ from the requested model if a fallback model was used.
+	Model string
+
+	// BaseUrl is the base URL of the gateway which served the request, among ClientOptions.BaseUrls.
+	// It is only set if BaseUrls is, and the request was sent to a gateway of BaseUrls.
+	BaseUrl string
+
+	// RequestId is the ID the gateway assigned to the request that served the call, from RequestIdHeader,
+	// to quote when reporting issues. It is empty if the gateway did not assign one.
+	RequestId string
+}
+
+type callMetadataKey struct{}
+
+// WithCallMetadata returns a copy of ctx which makes client calls fill in metadata once they succeed.
+// If the context is used for several calls, metadata holds the information for the latest successful one.
+func WithCallMetadata(ctx context.Context, metadata *CallMetadata) context.Context {
+	return context.WithValue(ctx, callMetadataKey{}, metadata)
+}
+
+// Returns the call metadata attached to ctx, or nil if there is none.
+func callMetadataFrom(ctx context.Context) *CallMetadata {
+	metadata, _ := ctx.Value(callMetadataKey{}).(*CallMetadata)
+	return metadata
+}
+
+// Records the request ID from the headers of a successful response in the call metadata attached to ctx, if any.
+func recordRequestId(ctx context.Context, header http.Header) {
+	if metadata := callMetadataFrom(ctx); metadata != nil {
+		metadata.RequestId = header.Get(RequestIdHeader)
+	}
+}
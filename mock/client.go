@@ -0,0 +1,313 @@
+// Package mock provides a mock of the Function Network Go SDK client, for unit tests of code that depends on sdk.FunctionClient.
+// Unlike the stubs in the sdktest package, which answer calls with plausible canned responses, the mock answers each method
+// with a function set by the test, and records every call so that tests can assert on the requests that were made.
+package mock
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// UnexpectedCallError is returned by the methods of Client whose function is not set.
+type UnexpectedCallError struct {
+	// Method is the name of the method that was called, such as "ChatComplete".
+	Method string
+}
+
+func (e *UnexpectedCallError) Error() string {
+	return fmt.Sprintf("unexpected call to %s: no mock function is set", e.Method)
+}
+
+// Call is a call made to a Client.
+type Call struct {
+	// Method is the name of the method that was called, such as "ChatComplete".
+	Method string
+
+	// Args are the arguments of the call, in order, excluding the context and call options.
+	Args []any
+}
+
+// Client is a mock implementation of sdk.FunctionClient.
+// Each method calls the function of the matching field, such as ChatCompleteFunc for ChatComplete, and returns its results.
+// If the function is not set, methods which return an error fail with an *UnexpectedCallError, and other methods return zero values.
+// The zero value is ready to use, and it is safe for concurrent use, provided that the functions are not changed during calls.
+type Client struct {
+	ChatCompleteFunc              func(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, opts ...sdk.CallOption) (*apigatewayv1.ChatCompleteResponse, error)
+	ChatCompleteStreamFunc        func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error)
+	EmbedFunc                     func(ctx context.Context, request *apigatewayv1.EmbedRequest, opts ...sdk.CallOption) (*apigatewayv1.EmbedResponse, error)
+	TextToImageFunc               func(ctx context.Context, request *apigatewayv1.TextToImageRequest, opts ...sdk.CallOption) (*apigatewayv1.TextToImageResponse, error)
+	TranscribeFunc                func(ctx context.Context, request *apigatewayv1.TranscribeRequest, opts ...sdk.CallOption) (*apigatewayv1.TranscribeResponse, error)
+	CompleteFunc                  func(ctx context.Context, request *sdk.ChatRequest, opts ...sdk.CallOption) (*sdk.ChatResponse, error)
+	CompleteStreamFunc            func(ctx context.Context, request *sdk.ChatRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error)
+	CreateEmbeddingFunc           func(ctx context.Context, request *sdk.EmbedRequest, opts ...sdk.CallOption) (*sdk.EmbedResponse, error)
+	GenerateImageFunc             func(ctx context.Context, request *sdk.ImageRequest, opts ...sdk.CallOption) (*sdk.ImageResponse, error)
+	TranscribeAudioFunc           func(ctx context.Context, request *sdk.TranscribeRequest, opts ...sdk.CallOption) (*sdk.TranscribeResponse, error)
+	ChatFunc                      func(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, stream bool) (string, *sdk.ResponseStream[apigatewayv1.ChatCompleteStreamResponse, string], error)
+	ChatCompleteStreamPersistFunc func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, w io.Writer) (string, error)
+	ChatCompleteStreamRawFunc     func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest) (*connect.ServerStreamForClient[apigatewayv1.ChatCompleteStreamResponse], error)
+	ForwardChatCompleteStreamFunc func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, dst *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error
+	CompareModelsFunc             func(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, models []string) (map[string]string, error)
+	EmbedBatchFunc                func(ctx context.Context, model string, inputs []string) ([][]float32, error)
+	EmbedBatchPartialFunc         func(ctx context.Context, model string, inputs []string) (map[int][]float32, error)
+	BenchmarkEmbedFunc            func(ctx context.Context, model string, sampleInputs []string, duration time.Duration) (*sdk.ThroughputReport, error)
+	TextToImageBatchFunc          func(ctx context.Context, prompts []string, shared *apigatewayv1.TextToImageRequest, concurrency int) ([]*apigatewayv1.TextToImageResponse, error)
+	FetchImageFunc                func(ctx context.Context, url string) (*http.Response, error)
+	SaveImageFunc                 func(ctx context.Context, url string, w io.Writer, options *sdk.SaveImageOptions) error
+	ContextWindowFunc             func(ctx context.Context, model string) (maxInput int, maxOutput int, err error)
+	CheckRequestSizeFunc          func(ctx context.Context, request proto.Message) error
+	SmokeTestFunc                 func(ctx context.Context, model string, modality sdk.Modality, options *sdk.SmokeTestOptions) (*sdk.SmokeResult, error)
+	ConcurrencyFunc               func() (limit int, inFlight int)
+	CancelAllFunc                 func()
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ sdk.FunctionClient = (*Client)(nil)
+
+// Records a call.
+func (m *Client) record(method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made so far, in order.
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made so far to the given method, in order.
+func (m *Client) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far.
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Client) ChatComplete(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, opts ...sdk.CallOption) (*apigatewayv1.ChatCompleteResponse, error) {
+	m.record("ChatComplete", request)
+	if m.ChatCompleteFunc == nil {
+		return nil, &UnexpectedCallError{Method: "ChatComplete"}
+	}
+	return m.ChatCompleteFunc(ctx, request, opts...)
+}
+
+func (m *Client) ChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error) {
+	m.record("ChatCompleteStream", request)
+	if m.ChatCompleteStreamFunc == nil {
+		return nil, &UnexpectedCallError{Method: "ChatCompleteStream"}
+	}
+	return m.ChatCompleteStreamFunc(ctx, request, opts...)
+}
+
+func (m *Client) Embed(ctx context.Context, request *apigatewayv1.EmbedRequest, opts ...sdk.CallOption) (*apigatewayv1.EmbedResponse, error) {
+	m.record("Embed", request)
+	if m.EmbedFunc == nil {
+		return nil, &UnexpectedCallError{Method: "Embed"}
+	}
+	return m.EmbedFunc(ctx, request, opts...)
+}
+
+func (m *Client) TextToImage(ctx context.Context, request *apigatewayv1.TextToImageRequest, opts ...sdk.CallOption) (*apigatewayv1.TextToImageResponse, error) {
+	m.record("TextToImage", request)
+	if m.TextToImageFunc == nil {
+		return nil, &UnexpectedCallError{Method: "TextToImage"}
+	}
+	return m.TextToImageFunc(ctx, request, opts...)
+}
+
+func (m *Client) Transcribe(ctx context.Context, request *apigatewayv1.TranscribeRequest, opts ...sdk.CallOption) (*apigatewayv1.TranscribeResponse, error) {
+	m.record("Transcribe", request)
+	if m.TranscribeFunc == nil {
+		return nil, &UnexpectedCallError{Method: "Transcribe"}
+	}
+	return m.TranscribeFunc(ctx, request, opts...)
+}
+
+func (m *Client) Complete(ctx context.Context, request *sdk.ChatRequest, opts ...sdk.CallOption) (*sdk.ChatResponse, error) {
+	m.record("Complete", request)
+	if m.CompleteFunc == nil {
+		return nil, &UnexpectedCallError{Method: "Complete"}
+	}
+	return m.CompleteFunc(ctx, request, opts...)
+}
+
+func (m *Client) CompleteStream(ctx context.Context, request *sdk.ChatRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error) {
+	m.record("CompleteStream", request)
+	if m.CompleteStreamFunc == nil {
+		return nil, &UnexpectedCallError{Method: "CompleteStream"}
+	}
+	return m.CompleteStreamFunc(ctx, request, opts...)
+}
+
+func (m *Client) CreateEmbedding(ctx context.Context, request *sdk.EmbedRequest, opts ...sdk.CallOption) (*sdk.EmbedResponse, error) {
+	m.record("CreateEmbedding", request)
+	if m.CreateEmbeddingFunc == nil {
+		return nil, &UnexpectedCallError{Method: "CreateEmbedding"}
+	}
+	return m.CreateEmbeddingFunc(ctx, request, opts...)
+}
+
+func (m *Client) GenerateImage(ctx context.Context, request *sdk.ImageRequest, opts ...sdk.CallOption) (*sdk.ImageResponse, error) {
+	m.record("GenerateImage", request)
+	if m.GenerateImageFunc == nil {
+		return nil, &UnexpectedCallError{Method: "GenerateImage"}
+	}
+	return m.GenerateImageFunc(ctx, request, opts...)
+}
+
+func (m *Client) TranscribeAudio(ctx context.Context, request *sdk.TranscribeRequest, opts ...sdk.CallOption) (*sdk.TranscribeResponse, error) {
+	m.record("TranscribeAudio", request)
+	if m.TranscribeAudioFunc == nil {
+		return nil, &UnexpectedCallError{Method: "TranscribeAudio"}
+	}
+	return m.TranscribeAudioFunc(ctx, request, opts...)
+}
+
+func (m *Client) Chat(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, stream bool) (string, *sdk.ResponseStream[apigatewayv1.ChatCompleteStreamResponse, string], error) {
+	m.record("Chat", request, stream)
+	if m.ChatFunc == nil {
+		return "", nil, &UnexpectedCallError{Method: "Chat"}
+	}
+	return m.ChatFunc(ctx, request, stream)
+}
+
+func (m *Client) ChatCompleteStreamPersist(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, w io.Writer) (string, error) {
+	m.record("ChatCompleteStreamPersist", request, w)
+	if m.ChatCompleteStreamPersistFunc == nil {
+		return "", &UnexpectedCallError{Method: "ChatCompleteStreamPersist"}
+	}
+	return m.ChatCompleteStreamPersistFunc(ctx, request, w)
+}
+
+func (m *Client) ChatCompleteStreamRaw(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest) (*connect.ServerStreamForClient[apigatewayv1.ChatCompleteStreamResponse], error) {
+	m.record("ChatCompleteStreamRaw", request)
+	if m.ChatCompleteStreamRawFunc == nil {
+		return nil, &UnexpectedCallError{Method: "ChatCompleteStreamRaw"}
+	}
+	return m.ChatCompleteStreamRawFunc(ctx, request)
+}
+
+func (m *Client) ForwardChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, dst *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+	m.record("ForwardChatCompleteStream", request, dst)
+	if m.ForwardChatCompleteStreamFunc == nil {
+		return &UnexpectedCallError{Method: "ForwardChatCompleteStream"}
+	}
+	return m.ForwardChatCompleteStreamFunc(ctx, request, dst)
+}
+
+func (m *Client) CompareModels(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, models []string) (map[string]string, error) {
+	m.record("CompareModels", request, models)
+	if m.CompareModelsFunc == nil {
+		return nil, &UnexpectedCallError{Method: "CompareModels"}
+	}
+	return m.CompareModelsFunc(ctx, request, models)
+}
+
+func (m *Client) EmbedBatch(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+	m.record("EmbedBatch", model, inputs)
+	if m.EmbedBatchFunc == nil {
+		return nil, &UnexpectedCallError{Method: "EmbedBatch"}
+	}
+	return m.EmbedBatchFunc(ctx, model, inputs)
+}
+
+func (m *Client) EmbedBatchPartial(ctx context.Context, model string, inputs []string) (map[int][]float32, error) {
+	m.record("EmbedBatchPartial", model, inputs)
+	if m.EmbedBatchPartialFunc == nil {
+		return nil, &UnexpectedCallError{Method: "EmbedBatchPartial"}
+	}
+	return m.EmbedBatchPartialFunc(ctx, model, inputs)
+}
+
+func (m *Client) BenchmarkEmbed(ctx context.Context, model string, sampleInputs []string, duration time.Duration) (*sdk.ThroughputReport, error) {
+	m.record("BenchmarkEmbed", model, sampleInputs, duration)
+	if m.BenchmarkEmbedFunc == nil {
+		return nil, &UnexpectedCallError{Method: "BenchmarkEmbed"}
+	}
+	return m.BenchmarkEmbedFunc(ctx, model, sampleInputs, duration)
+}
+
+func (m *Client) TextToImageBatch(ctx context.Context, prompts []string, shared *apigatewayv1.TextToImageRequest, concurrency int) ([]*apigatewayv1.TextToImageResponse, error) {
+	m.record("TextToImageBatch", prompts, shared, concurrency)
+	if m.TextToImageBatchFunc == nil {
+		return nil, &UnexpectedCallError{Method: "TextToImageBatch"}
+	}
+	return m.TextToImageBatchFunc(ctx, prompts, shared, concurrency)
+}
+
+func (m *Client) FetchImage(ctx context.Context, url string) (*http.Response, error) {
+	m.record("FetchImage", url)
+	if m.FetchImageFunc == nil {
+		return nil, &UnexpectedCallError{Method: "FetchImage"}
+	}
+	return m.FetchImageFunc(ctx, url)
+}
+
+func (m *Client) SaveImage(ctx context.Context, url string, w io.Writer, options *sdk.SaveImageOptions) error {
+	m.record("SaveImage", url, w, options)
+	if m.SaveImageFunc == nil {
+		return &UnexpectedCallError{Method: "SaveImage"}
+	}
+	return m.SaveImageFunc(ctx, url, w, options)
+}
+
+func (m *Client) ContextWindow(ctx context.Context, model string) (maxInput int, maxOutput int, err error) {
+	m.record("ContextWindow", model)
+	if m.ContextWindowFunc == nil {
+		return 0, 0, &UnexpectedCallError{Method: "ContextWindow"}
+	}
+	return m.ContextWindowFunc(ctx, model)
+}
+
+func (m *Client) CheckRequestSize(ctx context.Context, request proto.Message) error {
+	m.record("CheckRequestSize", request)
+	if m.CheckRequestSizeFunc == nil {
+		return &UnexpectedCallError{Method: "CheckRequestSize"}
+	}
+	return m.CheckRequestSizeFunc(ctx, request)
+}
+
+func (m *Client) SmokeTest(ctx context.Context, model string, modality sdk.Modality, options *sdk.SmokeTestOptions) (*sdk.SmokeResult, error) {
+	m.record("SmokeTest", model, modality, options)
+	if m.SmokeTestFunc == nil {
+		return nil, &UnexpectedCallError{Method: "SmokeTest"}
+	}
+	return m.SmokeTestFunc(ctx, model, modality, options)
+}
+
+func (m *Client) Concurrency() (limit int, inFlight int) {
+	m.record("Concurrency")
+	if m.ConcurrencyFunc == nil {
+		return 0, 0
+	}
+	return m.ConcurrencyFunc()
+}
+
+func (m *Client) CancelAll() {
+	m.record("CancelAll")
+	if m.CancelAllFunc != nil {
+		m.CancelAllFunc()
+	}
+}
@@ -0,0 +1,61 @@
+package mock_test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/mock"
+	"io"
+	"strings"
+)
+
+// Translate is application code which depends on sdk.FunctionClient rather than *sdk.Client, so that it can run against a mock.
+func Translate(ctx context.Context, client sdk.FunctionClient, text string) (string, error) {
+	res, err := client.ChatCompleteStream(ctx, &apigatewayv1.ChatCompleteStreamRequest{
+		Model:   "some-model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Translate to French: " + text}},
+	})
+	if err != nil {
+		return "", err
+	}
+	tokens, err := res.TokenStream.ReadAll()
+	return strings.Join(tokens, ""), err
+}
+
+func ExampleClient() {
+	client := &mock.Client{
+		ChatCompleteStreamFunc: func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error) {
+			return sdk.StaticChatCompleteStream("assistant", []string{"Bon", "jour"}, nil), nil
+		},
+	}
+
+	translation, _ := Translate(context.Background(), client, "Hello")
+	fmt.Println(translation)
+
+	calls := client.CallsTo("ChatCompleteStream")
+	fmt.Println(calls[0].Args[0].(*apigatewayv1.ChatCompleteStreamRequest).Message[0].Content)
+	// Output:
+	// Bonjour
+	// Translate to French: Hello
+}
+
+func ExampleClient_error() {
+	client := &mock.Client{
+		ChatCompleteStreamFunc: func(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error) {
+			// The stream fails after its first token.
+			return sdk.StaticChatCompleteStream("assistant", []string{"Bon"}, io.ErrUnexpectedEOF), nil
+		},
+	}
+
+	_, err := Translate(context.Background(), client, "Hello")
+	fmt.Println(errors.Is(err, io.ErrUnexpectedEOF))
+
+	// Methods without a mock function fail.
+	_, err = client.Embed(context.Background(), &apigatewayv1.EmbedRequest{})
+	fmt.Println(err)
+	// Output:
+	// true
+	// unexpected call to Embed: no mock function is set
+}
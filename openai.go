@@ -0,0 +1,119 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OpenAiMessageError is returned by MessagesFromOpenAiJson when a message cannot be converted.
+type OpenAiMessageError struct {
+	// Index is the zero-based index of the message in the array.
+	Index int
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *OpenAiMessageError) Error() string {
+	return fmt.Sprintf("OpenAI message %d: %s", e.Index, e.Reason)
+}
+
+// A message in the OpenAI chat format. Content is kept raw, as it may be a string, an array of parts, or null.
+type openAiMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// A content part of an OpenAI message.
+type openAiContentPart struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// MessagesFromOpenAiJson converts chat messages in the OpenAI format to messages that can be sent with ChatComplete.
+// data is either a JSON array of messages, or an object with a "messages" array, as used by OpenAI fine-tuning datasets.
+//
+// The content of a message may be a string, an array of text parts, which are concatenated, or null, which yields empty content.
+// Fields which have no equivalent in the SDK, such as name, tool_calls or tool_call_id, are ignored.
+// The "developer" role is converted to "system", and other roles are kept as-is.
+// If a message has no role, or has a content part which is not text, such as an image, a *OpenAiMessageError is returned.
+func MessagesFromOpenAiJson(data []byte) ([]*apigatewayv1.ChatCompleteMessage, error) {
+	var raw []openAiMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var dataset struct {
+			Messages []openAiMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(trimmed, &dataset); err != nil {
+			return nil, err
+		}
+		raw = dataset.Messages
+	} else if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	messages := make([]*apigatewayv1.ChatCompleteMessage, len(raw))
+	for i, message := range raw {
+		if message.Role == "" {
+			return nil, &OpenAiMessageError{Index: i, Reason: "the message has no role"}
+		}
+
+		content, err := openAiContent(message.Content)
+		if err != nil {
+			return nil, &OpenAiMessageError{Index: i, Reason: err.Error()}
+		}
+
+		role := message.Role
+		if role == "developer" {
+			role = "system"
+		}
+		messages[i] = &apigatewayv1.ChatCompleteMessage{Role: role, Content: content}
+	}
+
+	return messages, nil
+}
+
+// Returns the text of the content of an OpenAI message.
+func openAiContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []openAiContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("the content is neither a string nor an array of parts")
+	}
+
+	var content strings.Builder
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("unsupported content part of type %q", part.Type)
+		}
+		content.WriteString(part.Text)
+	}
+	return content.String(), nil
+}
+
+// MessagesToOpenAiJson converts chat messages to a JSON array of messages in the OpenAI format, with string content.
+// Nil messages are skipped.
+func MessagesToOpenAiJson(messages []*apigatewayv1.ChatCompleteMessage) ([]byte, error) {
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+
+	raw := make([]message, 0, len(messages))
+	for _, m := range messages {
+		if m != nil {
+			raw = append(raw, message{Role: m.Role, Content: m.Content})
+		}
+	}
+	return json.Marshal(raw)
+}
@@ -0,0 +1,297 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"crypto/tls"
+	"fmt"
+	"golang.org/x/time/rate"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// ClientOption configures a client created with New.
+// Each option validates its own arguments, so that a misconfiguration is reported by New, along with the option that caused it.
+type ClientOption func(options *ClientOptions) error
+
+// New creates a new Function Network client with the given API key, configured by opts, which are applied in order.
+// It is equivalent to NewClient with the resulting ClientOptions, and fails in the same cases, as well as when an option is invalid.
+// Options not covered by a ClientOption function can be set with WithOptions.
+func New(apiKey string, opts ...ClientOption) (*Client, error) {
+	options := ClientOptions{ApiKey: apiKey}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewClient(options)
+}
+
+// WithOptions applies fn to the client options, for options that have no dedicated ClientOption function.
+func WithOptions(fn func(options *ClientOptions)) ClientOption {
+	return func(options *ClientOptions) error {
+		fn(options)
+		return nil
+	}
+}
+
+// WithBaseUrl sets the API gateway base URL, which must be an absolute http or https URL.
+func WithBaseUrl(baseUrl string) ClientOption {
+	return func(options *ClientOptions) error {
+		if _, err := parseBaseUrl(baseUrl); err != nil {
+			return err
+		}
+
+		options.BaseUrl = baseUrl
+		return nil
+	}
+}
+
+// WithBaseUrls sets API gateway base URLs to fail over between, in order of preference, which must be absolute http or https URLs.
+// See ClientOptions.BaseUrls.
+func WithBaseUrls(baseUrls ...string) ClientOption {
+	return func(options *ClientOptions) error {
+		if len(baseUrls) == 0 {
+			return fmt.Errorf("at least one base URL is required")
+		}
+		for _, baseUrl := range baseUrls {
+			if _, err := parseBaseUrl(baseUrl); err != nil {
+				return err
+			}
+		}
+
+		options.BaseUrls = baseUrls
+		return nil
+	}
+}
+
+// WithCredentialsProvider sets a provider to get the API key from for every call, in place of the key passed to New,
+// which may then be empty. See ClientOptions.CredentialsProvider.
+func WithCredentialsProvider(provider CredentialsProvider) ClientOption {
+	return func(options *ClientOptions) error {
+		if provider == nil {
+			return fmt.Errorf("credentials provider must not be nil")
+		}
+
+		options.CredentialsProvider = provider
+		return nil
+	}
+}
+
+// WithHttpClient sets the HTTP client used for all requests, which must not be nil.
+func WithHttpClient(httpClient HttpClient) ClientOption {
+	return func(options *ClientOptions) error {
+		if httpClient == nil {
+			return fmt.Errorf("HTTP client must not be nil")
+		}
+
+		options.HttpClient = httpClient
+		return nil
+	}
+}
+
+// WithProxyUrl sends all requests through the proxy at proxyUrl, which must be an absolute http, https, socks5 or socks5h URL.
+// See ClientOptions.ProxyUrl.
+func WithProxyUrl(proxyUrl string) ClientOption {
+	return func(options *ClientOptions) error {
+		if _, err := parseProxyUrl(proxyUrl); err != nil {
+			return fmt.Errorf("invalid proxy URL %q: %w", proxyUrl, err)
+		}
+
+		options.ProxyUrl = proxyUrl
+		return nil
+	}
+}
+
+// WithTlsConfig sets the TLS configuration of connections to gateways, which must not be nil. See ClientOptions.TlsConfig.
+func WithTlsConfig(config *tls.Config) ClientOption {
+	return func(options *ClientOptions) error {
+		if config == nil {
+			return fmt.Errorf("TLS config must not be nil")
+		}
+
+		options.TlsConfig = config
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request, which must not be empty. See ClientOptions.UserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(options *ClientOptions) error {
+		if userAgent == "" {
+			return fmt.Errorf("user agent must not be empty")
+		}
+
+		options.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithDefaultHeaders adds headers sent with every request, on top of those added by previous calls. See ClientOptions.DefaultHeaders.
+func WithDefaultHeaders(headers map[string]string) ClientOption {
+	return func(options *ClientOptions) error {
+		merged := maps.Clone(options.DefaultHeaders)
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		maps.Copy(merged, headers)
+
+		options.DefaultHeaders = merged
+		return nil
+	}
+}
+
+// WithTimeout sets the maximum duration of each unary request, which must be positive. See ClientOptions.Timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(options *ClientOptions) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout %v must be positive", timeout)
+		}
+
+		options.Timeout = timeout
+		return nil
+	}
+}
+
+// WithCodec sets the message encoding used on the wire.
+func WithCodec(codec Codec) ClientOption {
+	return func(options *ClientOptions) error {
+		if codec != CodecProto && codec != CodecJson {
+			return fmt.Errorf("unknown codec %d", codec)
+		}
+
+		options.Codec = codec
+		return nil
+	}
+}
+
+// WithConnectProtocol uses the Connect protocol to talk to the API gateway, which is the default. See ProtocolConnect.
+func WithConnectProtocol() ClientOption {
+	return withProtocol(ProtocolConnect)
+}
+
+// WithGrpc uses the gRPC protocol to talk to the API gateway, which requires HTTP/2. See ProtocolGrpc.
+func WithGrpc() ClientOption {
+	return withProtocol(ProtocolGrpc)
+}
+
+// WithGrpcWeb uses the gRPC-Web protocol to talk to the API gateway. See ProtocolGrpcWeb.
+func WithGrpcWeb() ClientOption {
+	return withProtocol(ProtocolGrpcWeb)
+}
+
+// Sets the wire protocol.
+func withProtocol(protocol Protocol) ClientOption {
+	return func(options *ClientOptions) error {
+		options.Protocol = protocol
+		return nil
+	}
+}
+
+// WithGzip compresses requests with gzip. See ClientOptions.Compression.
+func WithGzip() ClientOption {
+	return WithCompression(CompressionGzip)
+}
+
+// WithCompression compresses requests with the named compression, which must not be empty. See ClientOptions.Compression.
+func WithCompression(name string) ClientOption {
+	return func(options *ClientOptions) error {
+		if name == "" {
+			return fmt.Errorf("compression name must not be empty")
+		}
+
+		options.Compression = name
+		return nil
+	}
+}
+
+// WithRateLimiter throttles outgoing requests for models that do not have a limiter in ClientOptions.ModelRateLimiters.
+func WithRateLimiter(limiter *rate.Limiter) ClientOption {
+	return func(options *ClientOptions) error {
+		options.RateLimiter = limiter
+		return nil
+	}
+}
+
+// WithRateLimit throttles all outgoing requests, including streams and retries, with a token bucket which allows
+// rps requests per second on average, and bursts of up to burst requests. Requests over the limit wait for their turn, or until
+// their context is done. rps must be positive, and burst at least 1.
+// It replaces any limiter set with WithRateLimiter, and is overridden for models in ClientOptions.ModelRateLimiters.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(options *ClientOptions) error {
+		if rps <= 0 || math.IsInf(rps, 0) || math.IsNaN(rps) {
+			return fmt.Errorf("rate limit %v must be a positive number of requests per second", rps)
+		}
+		if burst < 1 {
+			return fmt.Errorf("rate limit burst %d must be at least 1", burst)
+		}
+
+		options.RateLimiter = rate.NewLimiter(rate.Limit(rps), burst)
+		return nil
+	}
+}
+
+// WithFallbackModels sets the models to retry a request with, in order, when the requested model is unavailable.
+// See ClientOptions.FallbackModels.
+func WithFallbackModels(models ...string) ClientOption {
+	return func(options *ClientOptions) error {
+		options.FallbackModels = models
+		return nil
+	}
+}
+
+// WithRetry enables automatic retries of calls which fail with a transient error. See ClientOptions.Retry.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(options *ClientOptions) error {
+		options.Retry = &policy
+		return nil
+	}
+}
+
+// WithLogger logs a summary of every request to logger, which must not be nil. See ClientOptions.Logger.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(options *ClientOptions) error {
+		if logger == nil {
+			return fmt.Errorf("logger must not be nil")
+		}
+
+		options.Logger = logger
+		return nil
+	}
+}
+
+// WithDebug dumps every request and response to out, which must not be nil. See ClientOptions.Debug.
+func WithDebug(out io.Writer) ClientOption {
+	return func(options *ClientOptions) error {
+		if out == nil {
+			return fmt.Errorf("debug writer must not be nil")
+		}
+
+		options.Debug = out
+		return nil
+	}
+}
+
+// WithInterceptors appends Connect interceptors run around every request. See ClientOptions.Interceptors.
+func WithInterceptors(interceptors ...connect.Interceptor) ClientOption {
+	return func(options *ClientOptions) error {
+		if slices.Contains(interceptors, nil) {
+			return fmt.Errorf("interceptors must not be nil")
+		}
+
+		options.Interceptors = append(options.Interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithConnectOptions appends additional Connect client options. See ClientOptions.ConnectOptions.
+func WithConnectOptions(connectOptions ...connect.ClientOption) ClientOption {
+	return func(options *ClientOptions) error {
+		options.ConnectOptions = append(options.ConnectOptions, connectOptions...)
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+// Package otelfn integrates the Function Network Go SDK with OpenTelemetry.
+// It is a separate package so that applications which do not use OpenTelemetry do not depend on it.
+package otelfn
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"strings"
+	"time"
+)
+
+// Names of the instruments recorded by Metrics.
+// Where OpenTelemetry semantic conventions define an instrument, its name is used.
+const (
+	// RequestsMetric counts requests, including failed ones.
+	RequestsMetric = "function.client.requests"
+
+	// ErrorsMetric counts failed requests.
+	ErrorsMetric = "function.client.errors"
+
+	// DurationMetric is the duration of requests in milliseconds, as defined by the RPC semantic conventions.
+	// For streams, it is the time until the stream ended.
+	DurationMetric = "rpc.client.duration"
+
+	// TokenUsageMetric is the number of tokens used per request, as defined by the generative AI semantic conventions.
+	TokenUsageMetric = "gen_ai.client.token.usage"
+)
+
+// Metrics records SDK metrics using an OpenTelemetry meter.
+// Create one with NewMetrics, and attach it to a client with Instrument.
+type Metrics struct {
+	requests   metric.Int64Counter
+	errors     metric.Int64Counter
+	duration   metric.Float64Histogram
+	tokenUsage metric.Int64Histogram
+}
+
+// NewMetrics creates the SDK instruments using meter.
+// An error is returned if any of the instruments could not be created.
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	requests, err := meter.Int64Counter(RequestsMetric, metric.WithUnit("{request}"), metric.WithDescription("Number of requests made to the Function Network."))
+	if err != nil {
+		return nil, err
+	}
+	errorCount, err := meter.Int64Counter(ErrorsMetric, metric.WithUnit("{request}"), metric.WithDescription("Number of requests made to the Function Network that failed."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram(DurationMetric, metric.WithUnit("ms"), metric.WithDescription("Duration of requests made to the Function Network."))
+	if err != nil {
+		return nil, err
+	}
+	tokenUsage, err := meter.Int64Histogram(TokenUsageMetric, metric.WithUnit("{token}"), metric.WithDescription("Number of tokens used per request."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Metrics{
+		requests:   requests,
+		errors:     errorCount,
+		duration:   duration,
+		tokenUsage: tokenUsage,
+	}, nil
+}
+
+// Instrument configures client options to record metrics, by adding the interceptor returned by Interceptor to options.Interceptors,
+// and RecordUsage to options.OnUsage. An existing OnUsage callback is still called.
+// Clients created with sdk.NewClient before the call record no metrics, as the client reads its interceptors and hooks once.
+//
+// Since the interceptor runs for every request sent, a call that is retried, or retried with FallbackModels, is recorded once per attempt.
+func (m *Metrics) Instrument(options *sdk.ClientOptions) {
+	options.Interceptors = append(options.Interceptors, m.Interceptor())
+
+	onUsage := options.OnUsage
+	options.OnUsage = func(method string, model string, usage sdk.TokenUsage) {
+		m.RecordUsage(method, model, usage)
+		if onUsage != nil {
+			onUsage(method, model, usage)
+		}
+	}
+}
+
+// RecordUsage records token usage, and matches the signature of sdk.ClientOptions.OnUsage.
+// Prompt and completion tokens are recorded separately, as input and output tokens.
+func (m *Metrics) RecordUsage(method string, model string, usage sdk.TokenUsage) {
+	attributes := []attribute.KeyValue{
+		attribute.String("gen_ai.system", "function_network"),
+		attribute.String("gen_ai.request.model", model),
+		attribute.String("rpc.method", method),
+	}
+
+	if usage.PromptTokens > 0 {
+		m.tokenUsage.Record(context.Background(), int64(usage.PromptTokens), metric.WithAttributes(append(attributes, attribute.String("gen_ai.token.type", "input"))...))
+	}
+	if usage.CompletionTokens > 0 {
+		m.tokenUsage.Record(context.Background(), int64(usage.CompletionTokens), metric.WithAttributes(append(attributes, attribute.String("gen_ai.token.type", "output"))...))
+	}
+}
+
+// Interceptor returns a Connect interceptor which records the request count, error count, and duration of every request.
+// Most users should use Instrument instead, which also records token usage.
+func (m *Metrics) Interceptor() connect.Interceptor {
+	return &metricsInterceptor{metrics: m}
+}
+
+// Records a finished request.
+func (m *Metrics) record(ctx context.Context, procedure string, model string, start time.Time, err error) {
+	service, method := splitProcedure(procedure)
+	attributes := []attribute.KeyValue{
+		attribute.String("rpc.system", "connect_rpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+		attribute.String("gen_ai.request.model", model),
+	}
+	if err != nil {
+		attributes = append(attributes, attribute.String("rpc.connect_rpc.error_code", connect.CodeOf(err).String()))
+	}
+
+	options := metric.WithAttributes(attributes...)
+	m.requests.Add(ctx, 1, options)
+	if err != nil {
+		m.errors.Add(ctx, 1, options)
+	}
+	m.duration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), options)
+}
+
+// Splits a procedure such as "/apigateway.v1.APIGatewayService/ChatComplete" into its service and method names.
+func splitProcedure(procedure string) (string, string) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(procedure, "/"), "/")
+	return service, method
+}
+
+// metricsInterceptor records metrics for every request.
+type metricsInterceptor struct {
+	metrics *Metrics
+}
+
+func (i *metricsInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+		i.metrics.record(ctx, req.Spec().Procedure, sdk.RequestModel(req.Any()), start, err)
+		return res, err
+	}
+}
+
+func (i *metricsInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := &metricsConn{}
+		start := time.Now()
+		conn.endingConn = endingConn{
+			StreamingClientConn: next(ctx, spec),
+			end: func(err error) {
+				i.metrics.record(ctx, spec.Procedure, conn.model, start, err)
+			},
+		}
+		return conn
+	}
+}
+
+func (i *metricsInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// metricsConn records the model of a stream, so that the stream is recorded with it once it ends.
+type metricsConn struct {
+	endingConn
+
+	model string
+}
+
+func (c *metricsConn) Send(msg any) error {
+	c.model = sdk.RequestModel(msg)
+	return c.endingConn.Send(msg)
+}
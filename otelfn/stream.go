@@ -0,0 +1,46 @@
+package otelfn
+
+import (
+	"connectrpc.com/connect"
+	"errors"
+	"io"
+	"sync"
+)
+
+// endingConn calls end once a stream ends, with the error it failed with, or nil if it was read to its end or closed.
+// A stream closed before it ended counts as a success, as it was abandoned by the caller rather than failed.
+type endingConn struct {
+	connect.StreamingClientConn
+
+	end  func(err error)
+	once sync.Once
+}
+
+func (c *endingConn) Send(msg any) error {
+	err := c.StreamingClientConn.Send(msg)
+	if err != nil {
+		c.finish(err)
+	}
+	return err
+}
+
+func (c *endingConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if errors.Is(err, io.EOF) {
+		c.finish(nil)
+	} else if err != nil {
+		c.finish(err)
+	}
+	return err
+}
+
+func (c *endingConn) CloseResponse() error {
+	c.finish(nil)
+	return c.StreamingClientConn.CloseResponse()
+}
+
+func (c *endingConn) finish(err error) {
+	c.once.Do(func() {
+		c.end(err)
+	})
+}
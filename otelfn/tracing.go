@@ -0,0 +1,170 @@
+package otelfn
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+// TracerName is the name of the tracer that spans are created with.
+const TracerName = "github.com/fxnlabs/function-go-sdk/otelfn"
+
+// ChunkBatchSize is the number of stream chunks between the span events recorded for a stream,
+// so that long streams are observable without recording an event per token.
+const ChunkBatchSize = 32
+
+// Tracing creates OpenTelemetry spans for SDK requests.
+// Create one with NewTracing, and attach it to a client with Instrument.
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracing creates spans using a tracer from provider, and propagates trace context to the gateway with the global propagator.
+// If provider is nil, the global tracer provider is used.
+func NewTracing(provider trace.TracerProvider) *Tracing {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracing{
+		tracer:     provider.Tracer(TracerName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+}
+
+// Instrument configures client options to create spans, by adding the interceptor returned by Interceptor to options.Interceptors.
+// The options only take effect in clients created with sdk.NewClient afterwards.
+//
+// Since the interceptor runs for every request sent, a call that is retried, or retried with FallbackModels, has a span per attempt.
+func (t *Tracing) Instrument(options *sdk.ClientOptions) {
+	options.Interceptors = append(options.Interceptors, t.Interceptor())
+}
+
+// Interceptor returns a Connect interceptor which creates a client span for every request, following the RPC and generative AI
+// semantic conventions. Spans record the requested model and the token usage reported by the response.
+// Stream spans last until the stream ends, and record an event for the first token, then one every ChunkBatchSize chunks.
+func (t *Tracing) Interceptor() connect.Interceptor {
+	return &tracingInterceptor{tracing: t}
+}
+
+// Starts a span for a request to the given procedure.
+func (t *Tracing) start(ctx context.Context, procedure string) (context.Context, trace.Span) {
+	service, method := splitProcedure(procedure)
+	return t.tracer.Start(ctx, strings.TrimPrefix(procedure, "/"),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "connect_rpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+			attribute.String("gen_ai.system", "function_network"),
+		),
+	)
+}
+
+// Records the outcome of a request on its span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(attribute.String("rpc.connect_rpc.error_code", connect.CodeOf(err).String()))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Returns the attributes of the token usage reported in a response message.
+func usageAttributes(msg any) []attribute.KeyValue {
+	switch res := msg.(type) {
+	case *apigatewayv1.ChatCompleteResponse:
+		return []attribute.KeyValue{attribute.Int("gen_ai.usage.output_tokens", int(res.TokenCount))}
+	case *apigatewayv1.EmbedResponse:
+		if res.Usage != nil {
+			return []attribute.KeyValue{attribute.Int("gen_ai.usage.input_tokens", int(res.Usage.PromptTokens))}
+		}
+	}
+	return nil
+}
+
+// tracingInterceptor creates a span for every request.
+type tracingInterceptor struct {
+	tracing *Tracing
+}
+
+func (i *tracingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		ctx, span := i.tracing.start(ctx, req.Spec().Procedure)
+		span.SetAttributes(attribute.String("gen_ai.request.model", sdk.RequestModel(req.Any())))
+		i.tracing.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header()))
+
+		res, err := next(ctx, req)
+		if err == nil {
+			span.SetAttributes(usageAttributes(res.Any())...)
+		}
+		endSpan(span, err)
+		return res, err
+	}
+}
+
+func (i *tracingInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		ctx, span := i.tracing.start(ctx, spec.Procedure)
+		conn := next(ctx, spec)
+		i.tracing.propagator.Inject(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
+
+		tracingConn := &tracingConn{span: span, start: time.Now()}
+		tracingConn.endingConn = endingConn{StreamingClientConn: conn, end: tracingConn.endSpan}
+		return tracingConn
+	}
+}
+
+func (i *tracingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// tracingConn records the chunks of a stream on its span, and ends the span once the stream ends.
+type tracingConn struct {
+	endingConn
+
+	span  trace.Span
+	start time.Time
+
+	// The number of chunks received, including the role-only header chunk that starts every stream.
+	chunks int
+}
+
+func (c *tracingConn) Send(msg any) error {
+	c.span.SetAttributes(attribute.String("gen_ai.request.model", sdk.RequestModel(msg)))
+	return c.endingConn.Send(msg)
+}
+
+func (c *tracingConn) Receive(msg any) error {
+	err := c.endingConn.Receive(msg)
+	if err != nil {
+		return err
+	}
+
+	c.chunks++
+	switch {
+	case c.chunks == 2:
+		c.span.AddEvent("first_token", trace.WithAttributes(attribute.Int64("function.stream.first_token_latency_ms", time.Since(c.start).Milliseconds())))
+	case c.chunks > 2 && (c.chunks-1)%ChunkBatchSize == 0:
+		c.span.AddEvent("chunks", trace.WithAttributes(attribute.Int("function.stream.chunks", c.chunks-1)))
+	}
+	return nil
+}
+
+// Ends the span of the stream, with the number of tokens it received.
+func (c *tracingConn) endSpan(err error) {
+	if c.chunks > 1 {
+		c.span.SetAttributes(attribute.Int("gen_ai.usage.output_tokens", c.chunks-1))
+	}
+	endSpan(c.span, err)
+}
@@ -0,0 +1,167 @@
+// Package promfn exports Function Network Go SDK metrics to Prometheus.
+// It is a separate package so that applications which do not use Prometheus do not depend on it.
+package promfn
+
+import (
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Names of the metrics recorded by Metrics.
+const (
+	// RequestsMetric counts calls by method, model and status code, which is "ok" for successful calls.
+	// Error rates are the rate of calls with other codes.
+	RequestsMetric = "function_client_requests_total"
+
+	// DurationMetric is the duration of unary calls in seconds, by method and model.
+	DurationMetric = "function_client_request_duration_seconds"
+
+	// StreamDurationMetric is the duration of streams in seconds, by method and model, from opening them until they ended.
+	StreamDurationMetric = "function_client_stream_duration_seconds"
+
+	// TokensMetric counts tokens by method, model and type, which is "input" or "output". Its rate is the token throughput.
+	TokensMetric = "function_client_tokens_total"
+
+	// StreamTokensPerSecondMetric is the generation speed of streams read to their end, by model, as reported by TokenUsage.TokensPerSecond.
+	StreamTokensPerSecondMetric = "function_client_stream_tokens_per_second"
+)
+
+// Metrics records SDK metrics as Prometheus collectors.
+// Create one with NewMetrics, and attach it to a client with Instrument or WithMetrics.
+type Metrics struct {
+	requests              *prometheus.CounterVec
+	duration              *prometheus.HistogramVec
+	streamDuration        *prometheus.HistogramVec
+	tokens                *prometheus.CounterVec
+	streamTokensPerSecond *prometheus.HistogramVec
+}
+
+// NewMetrics creates the SDK collectors and registers them with registerer, or prometheus.DefaultRegisterer if it is nil.
+// Collectors already registered, such as by another client, are shared, so that several clients can record to the same registry.
+// An error is returned if any of the collectors could not be registered.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	requests, err := register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RequestsMetric,
+		Help: "Number of calls made to the Function Network.",
+	}, []string{"method", "model", "code"}))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    DurationMetric,
+		Help:    "Duration of unary calls made to the Function Network.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"method", "model"}))
+	if err != nil {
+		return nil, err
+	}
+	streamDuration, err := register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    StreamDurationMetric,
+		Help:    "Duration of streams from the Function Network.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+	}, []string{"method", "model"}))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: TokensMetric,
+		Help: "Number of tokens used by calls made to the Function Network.",
+	}, []string{"method", "model", "type"}))
+	if err != nil {
+		return nil, err
+	}
+	streamTokensPerSecond, err := register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    StreamTokensPerSecondMetric,
+		Help:    "Generation speed of streams from the Function Network.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+	}, []string{"model"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Metrics{
+		requests:              requests,
+		duration:              duration,
+		streamDuration:        streamDuration,
+		tokens:                tokens,
+		streamTokensPerSecond: streamTokensPerSecond,
+	}, nil
+}
+
+// Registers a collector, or returns the one already registered in its place.
+func register[C prometheus.Collector](registerer prometheus.Registerer, collector C) (C, error) {
+	err := registerer.Register(collector)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(C); ok {
+			return existing, nil
+		}
+	}
+	return collector, err
+}
+
+// WithMetrics returns a client option which records metrics to registerer, or prometheus.DefaultRegisterer if it is nil.
+// It is equivalent to creating Metrics with NewMetrics and calling Instrument, and fails if NewMetrics does.
+func WithMetrics(registerer prometheus.Registerer) sdk.ClientOption {
+	return func(options *sdk.ClientOptions) error {
+		metrics, err := NewMetrics(registerer)
+		if err != nil {
+			return err
+		}
+
+		metrics.Instrument(options)
+		return nil
+	}
+}
+
+// Instrument configures client options to record metrics, by setting options.OnResponse and options.OnError to Record.
+// Existing OnResponse and OnError hooks are still called.
+// sdk.NewClient reads the hooks when it creates a client, so clients created before the call are not instrumented.
+//
+// Since the hooks are called once per call, a call that is retried, or retried with FallbackModels, is recorded once,
+// with the model that served it, or was tried last.
+func (m *Metrics) Instrument(options *sdk.ClientOptions) {
+	options.OnResponse = chain(m.Record, options.OnResponse)
+	options.OnError = chain(m.Record, options.OnError)
+}
+
+// Returns a hook which calls first, then next if it is not nil.
+func chain(first func(context.Context, sdk.CallInfo), next func(context.Context, sdk.CallInfo)) func(context.Context, sdk.CallInfo) {
+	return func(ctx context.Context, info sdk.CallInfo) {
+		first(ctx, info)
+		if next != nil {
+			next(ctx, info)
+		}
+	}
+}
+
+// Record records a finished call, and matches the signature of sdk.ClientOptions.OnResponse and sdk.ClientOptions.OnError.
+func (m *Metrics) Record(ctx context.Context, info sdk.CallInfo) {
+	code := "ok"
+	if info.Err != nil {
+		code = info.Code.String()
+	}
+	m.requests.WithLabelValues(info.Method, info.Model, code).Inc()
+
+	if info.Stream {
+		m.streamDuration.WithLabelValues(info.Method, info.Model).Observe(info.Duration.Seconds())
+	} else {
+		m.duration.WithLabelValues(info.Method, info.Model).Observe(info.Duration.Seconds())
+	}
+
+	if info.Usage.PromptTokens > 0 {
+		m.tokens.WithLabelValues(info.Method, info.Model, "input").Add(float64(info.Usage.PromptTokens))
+	}
+	if info.Usage.CompletionTokens > 0 {
+		m.tokens.WithLabelValues(info.Method, info.Model, "output").Add(float64(info.Usage.CompletionTokens))
+	}
+	if info.Usage.TokensPerSecond > 0 {
+		m.streamTokensPerSecond.WithLabelValues(info.Model).Observe(info.Usage.TokensPerSecond)
+	}
+}
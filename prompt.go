@@ -0,0 +1,102 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// PromptTooLongError is returned when the messages of a chat request have more characters than ClientOptions.MaxPromptChars allows.
+// The request is rejected before it is sent.
+type PromptTooLongError struct {
+	// Chars is the total number of characters in the content of the request's messages.
+	Chars int
+
+	// MaxChars is the configured limit.
+	MaxChars int
+}
+
+func (e *PromptTooLongError) Error() string {
+	return fmt.Sprintf("prompt has %d characters, which exceeds the limit of %d characters", e.Chars, e.MaxChars)
+}
+
+// Returns the total number of characters in the message content of a chat request, and whether msg is a chat request.
+func promptChars(msg any) (int, bool) {
+	var messages []*apigatewayv1.ChatCompleteMessage
+	switch req := msg.(type) {
+	case *apigatewayv1.ChatCompleteRequest:
+		messages = req.Message
+	case *apigatewayv1.ChatCompleteStreamRequest:
+		messages = req.Message
+	default:
+		return 0, false
+	}
+
+	chars := 0
+	for _, message := range messages {
+		chars += utf8.RuneCountInString(message.GetContent())
+	}
+	return chars, true
+}
+
+// promptLengthInterceptor rejects chat requests whose messages are longer than a character limit.
+type promptLengthInterceptor struct {
+	// The maximum number of characters. If zero or negative, requests are not checked.
+	maxChars int
+}
+
+// Returns a *PromptTooLongError, wrapped as a Connect error, if msg is a chat request that exceeds the limit.
+func (i *promptLengthInterceptor) check(msg any) error {
+	if i.maxChars <= 0 {
+		return nil
+	}
+
+	chars, ok := promptChars(msg)
+	if !ok || chars <= i.maxChars {
+		return nil
+	}
+	return connect.NewError(connect.CodeInvalidArgument, &PromptTooLongError{
+		Chars:    chars,
+		MaxChars: i.maxChars,
+	})
+}
+
+func (i *promptLengthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.check(req.Any()); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func (i *promptLengthInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		return &promptLengthConn{
+			StreamingClientConn: next(ctx, spec),
+			interceptor:         i,
+		}
+	}
+}
+
+func (i *promptLengthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// promptLengthConn rejects the request message of a stream if it exceeds the prompt length limit.
+type promptLengthConn struct {
+	connect.StreamingClientConn
+
+	interceptor *promptLengthInterceptor
+}
+
+func (c *promptLengthConn) Send(msg any) error {
+	if err := c.interceptor.check(msg); err != nil {
+		return err
+	}
+
+	return c.StreamingClientConn.Send(msg)
+}
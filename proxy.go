@@ -0,0 +1,56 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+)
+
+// ChatCompleteStreamRaw starts a chat completion stream and returns the underlying Connect stream without reading from it.
+// This is intended for proxies and other advanced integrations that need direct access to every chunk, including the first one,
+// as well as the response headers and trailers.
+// Most users should use ChatCompleteStream instead.
+//
+// The caller owns the returned stream and must call Close on it once done, even if Receive returned false.
+func (c *Client) ChatCompleteStreamRaw(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest) (*connect.ServerStreamForClient[apigatewayv1.ChatCompleteStreamResponse], error) {
+	if request == nil {
+		return nil, c.methodError("ChatCompleteStreamRaw", NilRequestError)
+	}
+
+	res, err := c.service.ChatCompleteStream(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, c.methodError("ChatCompleteStreamRaw", err)
+	}
+
+	return res, nil
+}
+
+// ForwardChatCompleteStream starts a chat completion stream and forwards every chunk, as-is, to dst.
+// It is intended to be called from a Connect handler for a server that proxies Function, so that chunks
+// are passed through without being re-buffered.
+//
+// The call blocks until the upstream stream ends, dst fails, or ctx is done, and the upstream stream is always closed before returning.
+// If the upstream stream fails, its error is returned like those of other methods: as the ApiError matching its code,
+// such as a *RateLimitError, wrapped in a *MethodError for "ChatCompleteStreamRaw" if the stream could not be started,
+// or "ForwardChatCompleteStream" if it failed while being read, and then passed to ClientOptions.ErrorWrapper, if set.
+// All of these wrap the upstream *connect.Error, which Connect finds with errors.As, so returning the error from the handler
+// still forwards the original error code and message to the downstream client.
+// If sending to dst fails, that error is returned instead, which usually means the downstream client went away.
+//
+// Upstream response headers and trailers are not copied to dst, as they contain transport-specific values.
+// Use ChatCompleteStreamRaw if you need to forward them selectively.
+func (c *Client) ForwardChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, dst *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+	res, err := c.ChatCompleteStreamRaw(ctx, request)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for res.Receive() {
+		if err := dst.Send(res.Msg()); err != nil {
+			return err
+		}
+	}
+
+	return c.methodError("ForwardChatCompleteStream", res.Err())
+}
@@ -0,0 +1,52 @@
+package function_go_sdk
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContextBlockSeparator is the separator placed between chunks by BuildContextBlock.
+const ContextBlockSeparator = "\n\n---\n\n"
+
+// BuildContextBlock joins retrieved chunks, such as results of an embedding similarity search, into a single block of text
+// to inject into a chat prompt as a system or user message.
+//
+// Chunks are added in order, separated by ContextBlockSeparator, for as long as the total token count stays within maxTokens.
+// Since chunks are usually ordered by relevance, the first chunk that does not fit ends the block, rather than being skipped
+// in favor of smaller, less relevant chunks. The separators count towards the budget.
+//
+// countFn counts the tokens in a piece of text. If nil, EstimateTokens is used.
+func BuildContextBlock(chunks []string, maxTokens int, countFn func(string) int) string {
+	if countFn == nil {
+		countFn = EstimateTokens
+	}
+
+	var block strings.Builder
+	separatorTokens := countFn(ContextBlockSeparator)
+	used := 0
+
+	for i, chunk := range chunks {
+		cost := countFn(chunk)
+		if i > 0 {
+			cost += separatorTokens
+		}
+		if used+cost > maxTokens {
+			break
+		}
+
+		if i > 0 {
+			block.WriteString(ContextBlockSeparator)
+		}
+		block.WriteString(chunk)
+		used += cost
+	}
+
+	return block.String()
+}
+
+// EstimateTokens roughly estimates the number of tokens in text, at one token per four characters, rounded up.
+// It is the estimate used wherever the SDK counts tokens without a tokenizer, such as by ChatSession, BuildContextBlock
+// and CheckRequestSize, and by the tokens package for models without a registered tokenizer.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
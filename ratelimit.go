@@ -0,0 +1,91 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"golang.org/x/time/rate"
+)
+
+// modelRequest is implemented by every API gateway request message.
+type modelRequest interface {
+	GetModel() string
+}
+
+// RequestModel returns the model of an API gateway request message, such as one seen by an interceptor,
+// or an empty string if the message does not specify one.
+func RequestModel(msg any) string {
+	if req, ok := msg.(modelRequest); ok {
+		return req.GetModel()
+	}
+	return ""
+}
+
+// rateLimitInterceptor delays outgoing requests according to the limiter for the requested model.
+type rateLimitInterceptor struct {
+	// The limiter used for models that have no dedicated limiter.
+	// If nil, such requests are not limited.
+	defaultLimiter *rate.Limiter
+
+	// Limiters keyed by model name.
+	modelLimiters map[string]*rate.Limiter
+}
+
+func newRateLimitInterceptor(defaultLimiter *rate.Limiter, modelLimiters map[string]*rate.Limiter) *rateLimitInterceptor {
+	return &rateLimitInterceptor{
+		defaultLimiter: defaultLimiter,
+		modelLimiters:  modelLimiters,
+	}
+}
+
+// Blocks until the limiter for the request's model allows it to proceed, or ctx is done.
+func (i *rateLimitInterceptor) wait(ctx context.Context, msg any) error {
+	limiter, ok := i.modelLimiters[RequestModel(msg)]
+	if !ok {
+		limiter = i.defaultLimiter
+	}
+	if limiter == nil {
+		return nil
+	}
+
+	return limiter.Wait(ctx)
+}
+
+func (i *rateLimitInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.wait(ctx, req.Any()); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func (i *rateLimitInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		return &rateLimitedConn{
+			StreamingClientConn: next(ctx, spec),
+			ctx:                 ctx,
+			interceptor:         i,
+		}
+	}
+}
+
+func (i *rateLimitInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// rateLimitedConn delays sending the request message of a stream until the rate limiter allows it.
+type rateLimitedConn struct {
+	connect.StreamingClientConn
+
+	ctx         context.Context
+	interceptor *rateLimitInterceptor
+}
+
+func (c *rateLimitedConn) Send(msg any) error {
+	if err := c.interceptor.wait(c.ctx, msg); err != nil {
+		return err
+	}
+
+	return c.StreamingClientConn.Send(msg)
+}
@@ -0,0 +1,123 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Defaults of RetryPolicy fields left unset.
+const (
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff     = 5 * time.Second
+	DefaultRetryMultiplier     = 2
+)
+
+// RetryPolicy configures automatic retries of calls which fail with a transient error, with exponential backoff between attempts.
+// Unary calls are retried as a whole, while streams are only retried until their first chunk is received, so no tokens are ever repeated.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a call with each model, including the first one.
+	// Retries happen before falling back to the next of FallbackModels, if any. If it is 1 or less, calls are not retried.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. If unspecified, it defaults to DefaultRetryInitialBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. If unspecified, it defaults to DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry, which must be at least 1. If unspecified, it defaults to DefaultRetryMultiplier.
+	Multiplier float64
+
+	// Jitter is the fraction of each delay which is randomized, from 0 to 1, so that clients failing at the same time do not retry in lockstep.
+	// For example, with a jitter of 0.2, each delay is randomly shortened by up to 20%. If unspecified, delays are not randomized.
+	Jitter float64
+
+	// RetryableCodes are the error codes that trigger a retry.
+	// If unspecified, calls failing with connect.CodeUnavailable or connect.CodeDeadlineExceeded are retried.
+	// Calls rejected with a *RateLimitError are also retried if the gateway told when to retry, after waiting for its RetryAfter.
+	RetryableCodes []connect.Code
+}
+
+// Validates the policy and fills in its defaults, or returns nil if policy is nil.
+// The caller's policy is left untouched.
+func newRetryPolicy(policy *RetryPolicy) (*RetryPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	p := *policy
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+	if p.Multiplier < 1 {
+		return nil, fmt.Errorf("retry multiplier %v must be at least 1", p.Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return nil, fmt.Errorf("retry jitter %v must be between 0 and 1", p.Jitter)
+	}
+	if len(p.RetryableCodes) == 0 {
+		p.RetryableCodes = []connect.Code{connect.CodeUnavailable, connect.CodeDeadlineExceeded}
+	}
+
+	return &p, nil
+}
+
+// Returns whether a call which failed with err on the given attempt, starting at 1, should be attempted again.
+// Calls rejected with a *RateLimitError that tells when to retry are retried regardless of RetryableCodes.
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		return true
+	}
+	return slices.Contains(p.RetryableCodes, connect.CodeOf(err))
+}
+
+// Returns the delay after the given failed attempt, starting at 1.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt && delay < float64(p.MaxBackoff); i++ {
+		delay *= p.Multiplier
+	}
+	delay = min(delay, float64(p.MaxBackoff))
+	delay *= 1 - p.Jitter*rand.Float64()
+	return time.Duration(delay)
+}
+
+// Waits for the delay after the given attempt, which failed with err, or until ctx is done, in which case its error is returned.
+// If err is a *RateLimitError, the delay is at least its RetryAfter, and err is returned without waiting if ctx would be done by then,
+// as the retry could not succeed.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	delay := p.backoff(attempt)
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > delay {
+		delay = rateLimitErr.RetryAfter
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package function_go_sdk
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+)
+
+// routedService sends each method to the service client for its modality, for deployments which serve modalities from separate gateways.
+type routedService struct {
+	chat       apigatewayv1connect.APIGatewayServiceClient
+	embed      apigatewayv1connect.APIGatewayServiceClient
+	image      apigatewayv1connect.APIGatewayServiceClient
+	transcribe apigatewayv1connect.APIGatewayServiceClient
+}
+
+// Creates the service client for the client options, which routes modalities with their own base URL to a separate service client.
+// If no modality has its own base URL, a single service client is returned.
+func newRoutedService(httpClient HttpClient, options ClientOptions, baseUrl string, connectOptions []connect.ClientOption) apigatewayv1connect.APIGatewayServiceClient {
+	services := map[string]apigatewayv1connect.APIGatewayServiceClient{}
+	serviceFor := func(modalityBaseUrl string) apigatewayv1connect.APIGatewayServiceClient {
+		if modalityBaseUrl == "" {
+			modalityBaseUrl = baseUrl
+		}
+		// Modalities sharing a base URL share a service client.
+		if service, ok := services[modalityBaseUrl]; ok {
+			return service
+		}
+		service := apigatewayv1connect.NewAPIGatewayServiceClient(httpClient, modalityBaseUrl, connectOptions...)
+		services[modalityBaseUrl] = service
+		return service
+	}
+
+	routed := &routedService{
+		chat:       serviceFor(options.ChatBaseUrl),
+		embed:      serviceFor(options.EmbedBaseUrl),
+		image:      serviceFor(options.ImageBaseUrl),
+		transcribe: serviceFor(options.TranscribeBaseUrl),
+	}
+	if len(services) == 1 {
+		return routed.chat
+	}
+	return routed
+}
+
+func (s *routedService) ChatComplete(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+	return s.chat.ChatComplete(ctx, req)
+}
+
+func (s *routedService) ChatCompleteStream(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest]) (*connect.ServerStreamForClient[apigatewayv1.ChatCompleteStreamResponse], error) {
+	return s.chat.ChatCompleteStream(ctx, req)
+}
+
+func (s *routedService) Embed(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+	return s.embed.Embed(ctx, req)
+}
+
+func (s *routedService) TextToImage(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+	return s.image.TextToImage(ctx, req)
+}
+
+func (s *routedService) Transcribe(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+	return s.transcribe.Transcribe(ctx, req)
+}
@@ -0,0 +1,220 @@
+package sdktest_test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/sdktest"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Summarize is application code which depends on sdk.API rather than *sdk.Client, so that it can run against a stub.
+func Summarize(ctx context.Context, api sdk.API, text string) (string, error) {
+	res, err := api.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{
+		Model: "some-model",
+		Message: []*apigatewayv1.ChatCompleteMessage{
+			{Role: "system", Content: "Summarize the user's text."},
+			{Role: "user", Content: text},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Response.Content, nil
+}
+
+func ExampleStubClient() {
+	// By default, the stub echoes the last message back.
+	stub := sdktest.StubClient(sdktest.StubConfig{})
+
+	summary, _ := Summarize(context.Background(), stub, "A long story.")
+	fmt.Println(summary)
+	// Output: A long story.
+}
+
+func ExampleStubClient_chatReply() {
+	stub := sdktest.StubClient(sdktest.StubConfig{
+		ChatReply: func(messages []*apigatewayv1.ChatCompleteMessage) string {
+			return strings.ToUpper(messages[len(messages)-1].Content)
+		},
+	})
+
+	res, _ := stub.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "hello stub world"}},
+	})
+	for {
+		token, err := res.TokenStream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		fmt.Printf("%q\n", token)
+	}
+	// Output:
+	// "HELLO"
+	// " STUB"
+	// " WORLD"
+}
+
+func ExampleStubClient_embed() {
+	stub := sdktest.StubClient(sdktest.StubConfig{EmbeddingDimensions: 4})
+
+	first, _ := stub.Embed(context.Background(), &apigatewayv1.EmbedRequest{Input: "same input"})
+	second, _ := stub.Embed(context.Background(), &apigatewayv1.EmbedRequest{Input: "same input"})
+
+	fmt.Println(len(first.Data[0].Embedding))
+	fmt.Println(fmt.Sprint(first.Data[0].Embedding) == fmt.Sprint(second.Data[0].Embedding))
+	// Output:
+	// 4
+	// true
+}
+
+func ExampleNewServer() {
+	server := sdktest.NewServer(sdktest.ServerConfig{
+		Header: func(procedure string) http.Header {
+			return http.Header{"X-Request-Id": {"req-123"}}
+		},
+		Trailer: func(procedure string) http.Header {
+			return http.Header{"X-Tokens-Generated": {"3"}}
+		},
+	})
+	defer server.Close()
+
+	client, _ := sdk.NewClient(sdk.ClientOptions{ApiKey: "test-key", BaseUrl: server.Url})
+	stream, _ := client.ChatCompleteStreamRaw(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "one two three"}},
+	})
+	defer stream.Close()
+
+	for stream.Receive() {
+	}
+	fmt.Println(stream.ResponseHeader().Get("X-Request-Id"))
+	fmt.Println(stream.ResponseTrailer().Get("X-Tokens-Generated"))
+	// Output:
+	// req-123
+	// 3
+}
+
+func ExampleNewServer_scripted() {
+	// Header is called for every call, so it can simulate a rate-limit counter which decreases with each call.
+	remaining := 3
+	server := sdktest.NewServer(sdktest.ServerConfig{
+		Header: func(procedure string) http.Header {
+			remaining--
+			return http.Header{"X-Ratelimit-Remaining": {strconv.Itoa(remaining)}}
+		},
+	})
+	defer server.Close()
+
+	// Application code can observe headers of unary calls through a Connect interceptor.
+	observe := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			res, err := next(ctx, req)
+			if err == nil {
+				fmt.Println("remaining:", res.Header().Get("X-Ratelimit-Remaining"))
+			}
+			return res, err
+		}
+	})
+	client, _ := sdk.NewClient(sdk.ClientOptions{
+		ApiKey:         "test-key",
+		BaseUrl:        server.Url,
+		ConnectOptions: []connect.ClientOption{connect.WithInterceptors(observe)},
+	})
+
+	for range 2 {
+		client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "some-model", Input: "text"})
+	}
+	// Output:
+	// remaining: 2
+	// remaining: 1
+}
+
+func ExampleNewRecorder() {
+	dir, _ := os.MkdirTemp("", "fixtures")
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "chat.json")
+	request := &apigatewayv1.ChatCompleteStreamRequest{
+		Model:   "some-model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "one two three"}},
+	}
+
+	// Record the interactions with the gateway once, such as when the fixture is missing or must be refreshed.
+	server := sdktest.NewServer(sdktest.ServerConfig{})
+	recorder, _ := sdktest.NewRecorder(fixture, sdktest.RecorderOptions{Mode: sdktest.RecordMode})
+	client, _ := sdk.New("secret-key", sdk.WithBaseUrl(server.Url), sdk.WithHttpClient(recorder))
+	res, _ := client.ChatCompleteStream(context.Background(), request)
+	res.TokenStream.ReadAll()
+	recorder.Save()
+	server.Close()
+
+	data, _ := os.ReadFile(fixture)
+	fmt.Println(strings.Contains(string(data), "secret-key"))
+
+	// Replay them in CI, without the gateway or the API key.
+	recorder, _ = sdktest.NewRecorder(fixture, sdktest.RecorderOptions{})
+	client, _ = sdk.New("any-key", sdk.WithBaseUrl("http://gateway.invalid"), sdk.WithHttpClient(recorder))
+	res, _ = client.ChatCompleteStream(context.Background(), request)
+	tokens, err := res.TokenStream.ReadAll()
+	fmt.Printf("%q %v\n", tokens, err)
+
+	// Requests that were not recorded fail.
+	_, err = client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "some-model", Input: "text"})
+	var noRecordingErr *sdktest.NoRecordingError
+	fmt.Println(errors.As(err, &noRecordingErr))
+	// Output:
+	// false
+	// ["one" " two" " three"] <nil>
+	// true
+}
+
+func ExampleStub_On() {
+	stub := sdktest.StubClient(sdktest.StubConfig{})
+	// The second call fails, as if the gateway were overloaded.
+	stub.On("ChatComplete", 2, sdktest.Script{Err: connect.NewError(connect.CodeUnavailable, errors.New("overloaded"))})
+
+	for range 3 {
+		_, err := Summarize(context.Background(), stub, "text")
+		fmt.Println(err)
+	}
+	fmt.Println(stub.Calls("ChatComplete"))
+	// Output:
+	// <nil>
+	// unavailable: overloaded
+	// <nil>
+	// 3
+}
+
+func ExampleStub_On_partialStream() {
+	stub := sdktest.StubClient(sdktest.StubConfig{})
+	// Every stream is cut off after two tokens.
+	stub.On("ChatCompleteStream", 0, sdktest.Script{Response: []string{"one", " two", " three"}, PartialTokens: 2})
+
+	res, _ := stub.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{})
+	tokens, err := res.TokenStream.ReadAll()
+	fmt.Printf("%q\n", tokens)
+	fmt.Println(errors.Is(err, sdk.TruncatedStreamResponseError))
+	// Output:
+	// ["one" " two"]
+	// true
+}
+
+func ExampleStub_On_delay() {
+	stub := sdktest.StubClient(sdktest.StubConfig{})
+	stub.On("Embed", 0, sdktest.Script{Delay: time.Minute})
+
+	// The caller gives up before the slow call returns.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := stub.Embed(ctx, &apigatewayv1.EmbedRequest{Input: "text"})
+	fmt.Println(err)
+	// Output: context deadline exceeded
+}
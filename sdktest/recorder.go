@@ -0,0 +1,343 @@
+package sdktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RecorderMode is whether a Recorder records or replays interactions with the gateway.
+type RecorderMode int
+
+const (
+	// ReplayMode serves requests from the recorded interactions of the fixture file, without network access.
+	ReplayMode RecorderMode = iota
+
+	// RecordMode sends requests to the gateway and records them, to write to the fixture file with Recorder.Save.
+	RecordMode
+)
+
+// ScrubbedHeaders are the request headers whose values are replaced with ScrubbedValue in fixture files, as they hold credentials.
+var ScrubbedHeaders = []string{"X-Api-Key", "Authorization", "Proxy-Authorization"}
+
+// ScrubbedValue is the value recorded in place of credentials.
+const ScrubbedValue = "[SCRUBBED]"
+
+// RecorderOptions configures a Recorder.
+type RecorderOptions struct {
+	// Mode is whether the recorder records or replays interactions. If unspecified, it defaults to ReplayMode.
+	Mode RecorderMode
+
+	// Client sends requests in RecordMode. If unspecified, http.DefaultClient is used.
+	Client sdk.HttpClient
+
+	// ScrubHeaders are headers to scrub from recorded requests and responses in addition to ScrubbedHeaders,
+	// such as headers holding tokens or personal data.
+	ScrubHeaders []string
+
+	// ReplayTiming makes replayed responses wait for the recorded delay before each chunk of their body,
+	// to reproduce the timing of streams. If unset, responses are replayed as fast as they are read.
+	ReplayTiming bool
+}
+
+// NoRecordingError is returned by a Recorder in ReplayMode for a request that does not match any recorded interaction.
+type NoRecordingError struct {
+	// Method and Path are the HTTP method and URL path of the request.
+	Method string
+	Path   string
+}
+
+func (e *NoRecordingError) Error() string {
+	return fmt.Sprintf("no recorded interaction matches %s %s", e.Method, e.Path)
+}
+
+// Recorder is an sdk.HttpClient which records interactions with the gateway to a fixture file, and replays them deterministically,
+// so that tests can run against real gateway responses without network access or an API key, such as in CI.
+// Use it as sdk.ClientOptions.HttpClient. Create one with NewRecorder.
+//
+// Credentials are scrubbed from recorded headers, so fixture files can be committed. Response bodies are recorded as they are read,
+// chunk by chunk, with the delay before each chunk, so that streams are replayed chunk by chunk too.
+// In ReplayMode, a request is served by the first interaction not replayed yet with the same method, URL path and body;
+// the host of the URL is ignored, so fixtures can be replayed against any base URL.
+type Recorder struct {
+	path    string
+	options RecorderOptions
+
+	mu           sync.Mutex
+	interactions []*interaction
+	replayed     []bool
+}
+
+// The contents of a fixture file.
+type fixture struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+// interaction is a recorded request and its response.
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+type recordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Proto      string       `json:"proto"`
+	Header     http.Header  `json:"header"`
+	Trailer    http.Header  `json:"trailer,omitempty"`
+	Chunks     []*bodyChunk `json:"chunks"`
+}
+
+// bodyChunk is a chunk of a response body, as it was read.
+type bodyChunk struct {
+	// DelayMs is the time between the previous chunk, or the response headers for the first chunk, and this chunk, in milliseconds.
+	DelayMs int64  `json:"delay_ms"`
+	Data    []byte `json:"data"`
+}
+
+// NewRecorder creates a recorder of the fixture file at path. In ReplayMode, the file is loaded, and an error is returned
+// if it cannot be read. In RecordMode, the file is only written by Save.
+func NewRecorder(path string, options RecorderOptions) (*Recorder, error) {
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	r := &Recorder{path: path, options: options}
+	if options.Mode == RecordMode {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("could not parse fixture %s: %w", path, err)
+	}
+	r.interactions = f.Interactions
+	r.replayed = make([]bool, len(f.Interactions))
+	return r, nil
+}
+
+// Save writes the interactions recorded so far to the fixture file, replacing it. It does nothing in ReplayMode.
+// An interaction is recorded once its response body has been read to its end or closed, so all responses should be
+// closed before calling Save.
+func (r *Recorder) Save() error {
+	if r.options.Mode != RecordMode {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(fixture{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Do records or replays a request, depending on the mode.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.options.Mode == RecordMode {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+// Returns a copy of header, with the values of scrubbed headers replaced with ScrubbedValue.
+func (r *Recorder) scrub(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for _, key := range slices.Concat(ScrubbedHeaders, r.options.ScrubHeaders) {
+		if len(scrubbed.Values(key)) > 0 {
+			scrubbed.Set(key, ScrubbedValue)
+		}
+	}
+	return scrubbed
+}
+
+// Sends a request, and records it along with its response once the response body has been read.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	res, err := r.options.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := &interaction{
+		Request: recordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Header: r.scrub(req.Header),
+			Body:   body,
+		},
+		Response: recordedResponse{
+			StatusCode: res.StatusCode,
+			Proto:      res.Proto,
+			Header:     r.scrub(res.Header),
+		},
+	}
+	res.Body = &recordingBody{
+		body:        res.Body,
+		response:    res,
+		recorder:    r,
+		interaction: recorded,
+		last:        time.Now(),
+	}
+	return res, nil
+}
+
+// recordingBody records the chunks of a response body as they are read, and the interaction once the body is read or closed.
+type recordingBody struct {
+	body        io.ReadCloser
+	response    *http.Response
+	recorder    *Recorder
+	interaction *interaction
+
+	// Guards the fields below, as the body may be closed while it is read.
+	mu       sync.Mutex
+	last     time.Time
+	finished bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.mu.Lock()
+		if !b.finished {
+			now := time.Now()
+			b.interaction.Response.Chunks = append(b.interaction.Response.Chunks, &bodyChunk{
+				DelayMs: now.Sub(b.last).Milliseconds(),
+				Data:    bytes.Clone(p[:n]),
+			})
+			b.last = now
+		}
+		b.mu.Unlock()
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.body.Close()
+}
+
+// Records the interaction, once, along with the response trailers, which are only available once the body has been read.
+func (b *recordingBody) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	b.finished = true
+
+	if len(b.response.Trailer) > 0 {
+		b.interaction.Response.Trailer = b.recorder.scrub(b.response.Trailer)
+	}
+	b.recorder.mu.Lock()
+	defer b.recorder.mu.Unlock()
+	b.recorder.interactions = append(b.recorder.interactions, b.interaction)
+}
+
+// Serves a request from the first matching interaction not replayed yet.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	var match *interaction
+	for i, recorded := range r.interactions {
+		if !r.replayed[i] && recorded.Request.Method == req.Method && recorded.Request.Path == req.URL.Path && bytes.Equal(recorded.Request.Body, body) {
+			r.replayed[i] = true
+			match = recorded
+			break
+		}
+	}
+	r.mu.Unlock()
+	if match == nil {
+		return nil, &NoRecordingError{Method: req.Method, Path: req.URL.Path}
+	}
+
+	res := &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         match.Response.Proto,
+		Header:        match.Response.Header.Clone(),
+		Trailer:       match.Response.Trailer.Clone(),
+		ContentLength: -1,
+		Request:       req,
+		Body: &replayBody{
+			ctx:    req.Context(),
+			chunks: match.Response.Chunks,
+			timing: r.options.ReplayTiming,
+		},
+	}
+	res.ProtoMajor, res.ProtoMinor, _ = http.ParseHTTPVersion(res.Proto)
+	if res.Header == nil {
+		res.Header = http.Header{}
+	}
+	return res, nil
+}
+
+// replayBody serves the recorded chunks of a response body, waiting for their delay if timing is set.
+type replayBody struct {
+	ctx    context.Context
+	chunks []*bodyChunk
+	timing bool
+
+	// The unread part of the current chunk.
+	current []byte
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	for len(b.current) == 0 {
+		if len(b.chunks) == 0 {
+			return 0, io.EOF
+		}
+		chunk := b.chunks[0]
+		if b.timing && chunk.DelayMs > 0 {
+			timer := time.NewTimer(time.Duration(chunk.DelayMs) * time.Millisecond)
+			select {
+			case <-timer.C:
+			case <-b.ctx.Done():
+				timer.Stop()
+				return 0, b.ctx.Err()
+			}
+		}
+		b.current, b.chunks = chunk.Data, b.chunks[1:]
+	}
+
+	n := copy(p, b.current)
+	b.current = b.current[n:]
+	return n, nil
+}
+
+func (b *replayBody) Close() error {
+	b.chunks = nil
+	b.current = nil
+	return nil
+}
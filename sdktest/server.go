@@ -0,0 +1,137 @@
+package sdktest
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"net/http"
+	"net/http/httptest"
+)
+
+// ServerConfig configures a test server.
+type ServerConfig struct {
+	// Stub configures the canned responses, as for StubClient.
+	Stub StubConfig
+
+	// Header, if set, is called for every call with its procedure, such as "/apigateway.v1.APIGatewayService/ChatComplete",
+	// and the returned values are added to the response headers. This simulates gateway metadata such as request IDs.
+	// Since it is called for every call, it can script values that change over time, such as a decreasing rate-limit counter.
+	Header func(procedure string) http.Header
+
+	// Trailer, if set, is called for every call with its procedure, and the returned values are added to the response trailers.
+	// For ChatCompleteStream, it is called once all chunks were sent, so it can report values for the whole stream.
+	Trailer func(procedure string) http.Header
+}
+
+// Server is an API gateway served over HTTP on the loopback interface, which answers calls with canned responses.
+// Unlike a stub client, it exercises a real *sdk.Client end to end, including its interceptors and the response headers and trailers.
+// Create one with NewServer, and close it once done.
+type Server struct {
+	// Url is the base URL of the server, to use as sdk.ClientOptions.BaseUrl.
+	Url string
+
+	server *httptest.Server
+}
+
+// NewServer starts a test server which answers every call according to config.
+// Streamed chat replies are sent one word per chunk, after a first chunk with only the role, as the real gateway does.
+func NewServer(config ServerConfig) *Server {
+	handler := &serverHandler{
+		stub:   StubClient(config.Stub),
+		config: config,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(handler))
+	server := httptest.NewServer(mux)
+
+	return &Server{
+		Url:    server.URL,
+		server: server,
+	}
+}
+
+// Close shuts the server down, and blocks until all outstanding calls have completed.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// serverHandler answers calls with the responses of a stub, adding the configured headers and trailers.
+type serverHandler struct {
+	stub   *Stub
+	config ServerConfig
+}
+
+var _ apigatewayv1connect.APIGatewayServiceHandler = (*serverHandler)(nil)
+
+// Adds the configured headers to header, and the configured trailers to trailer, for the given procedure.
+func (h *serverHandler) addMetadata(procedure string, header http.Header, trailer http.Header) {
+	if h.config.Header != nil {
+		addValues(header, h.config.Header(procedure))
+	}
+	if h.config.Trailer != nil {
+		addValues(trailer, h.config.Trailer(procedure))
+	}
+}
+
+func addValues(dst http.Header, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
+// Wraps a stub response in a Connect response with the configured metadata, or returns the stub's error.
+func respond[T any](h *serverHandler, procedure string, msg *T, err error) (*connect.Response[T], error) {
+	if err != nil {
+		return nil, err
+	}
+
+	res := connect.NewResponse(msg)
+	h.addMetadata(procedure, res.Header(), res.Trailer())
+	return res, nil
+}
+
+func (h *serverHandler) ChatComplete(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+	msg, err := h.stub.ChatComplete(ctx, req.Msg)
+	return respond(h, req.Spec().Procedure, msg, err)
+}
+
+func (h *serverHandler) ChatCompleteStream(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+	procedure := req.Spec().Procedure
+	if h.config.Header != nil {
+		addValues(stream.ResponseHeader(), h.config.Header(procedure))
+	}
+
+	chunks := append([]string{""}, splitWords(h.stub.config.ChatReply(req.Msg.Message))...)
+	for _, chunk := range chunks {
+		err := stream.Send(&apigatewayv1.ChatCompleteStreamResponse{
+			Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: chunk},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if h.config.Trailer != nil {
+		addValues(stream.ResponseTrailer(), h.config.Trailer(procedure))
+	}
+	return nil
+}
+
+func (h *serverHandler) Embed(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+	msg, err := h.stub.Embed(ctx, req.Msg)
+	return respond(h, req.Spec().Procedure, msg, err)
+}
+
+func (h *serverHandler) TextToImage(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+	msg, err := h.stub.TextToImage(ctx, req.Msg)
+	return respond(h, req.Spec().Procedure, msg, err)
+}
+
+func (h *serverHandler) Transcribe(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+	msg, err := h.stub.Transcribe(ctx, req.Msg)
+	return respond(h, req.Spec().Procedure, msg, err)
+}
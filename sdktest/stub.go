@@ -0,0 +1,335 @@
+// Package sdktest provides test doubles for the Function Network Go SDK,
+// so that code using the SDK can be developed and tested without network access.
+package sdktest
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEmbeddingDimensions is the number of dimensions of stub embeddings when StubConfig.EmbeddingDimensions is unset.
+const DefaultEmbeddingDimensions = 8
+
+// DefaultImageUrl is the image URL returned by stub image generation when StubConfig.ImageUrl is unset.
+const DefaultImageUrl = "https://example.com/stub.png"
+
+// DefaultTranscript is the transcript returned by stub transcription when StubConfig.Transcript is unset.
+const DefaultTranscript = "This is a stub transcript."
+
+// StubConfig configures the canned responses of a stub client.
+// Every field is optional, and has a sensible default.
+type StubConfig struct {
+	// ChatReply generates the reply content for a chat request, for both ChatComplete and ChatCompleteStream.
+	// If unset, the content of the last message is echoed back.
+	ChatReply func(messages []*apigatewayv1.ChatCompleteMessage) string
+
+	// EmbeddingDimensions is the number of dimensions of embeddings.
+	// Embeddings are derived from a hash of the input, so identical inputs always produce identical embeddings.
+	// If unset, DefaultEmbeddingDimensions is used.
+	EmbeddingDimensions int
+
+	// ImageUrl is the URL returned for every generated image.
+	// If unset, DefaultImageUrl is used.
+	ImageUrl string
+
+	// Transcript is the text returned for every transcription.
+	// If unset, DefaultTranscript is used.
+	Transcript string
+}
+
+// Script programs the behavior of a call to a stub, such as to inject a failure, so that callers' handling of retries,
+// cancellation and truncated streams can be tested. Program scripts with Stub.On.
+type Script struct {
+	// Delay is the time the call takes before returning. If the context of the call is done first, the call fails with its error.
+	Delay time.Duration
+
+	// Response, if set, is returned instead of the canned response. It must be the response type of the method,
+	// such as *apigatewayv1.ChatCompleteResponse for ChatComplete, or, for ChatCompleteStream, a []string of the tokens to stream.
+	Response any
+
+	// Err, if set, makes the call fail with it. For ChatCompleteStream, if PartialTokens is set,
+	// the stream is opened and fails with Err after its first PartialTokens tokens instead.
+	Err error
+
+	// PartialTokens, if set, truncates the stream of ChatCompleteStream after that many tokens, at which point reading it fails
+	// with Err, or with sdk.TruncatedStreamResponseError if Err is unset. It is ignored for other methods.
+	PartialTokens int
+}
+
+// ScriptResponseTypeError is returned by a stub call whose Script.Response is not of the response type of the method.
+type ScriptResponseTypeError struct {
+	// Method is the name of the method, such as "ChatComplete".
+	Method string
+
+	// Response is the scripted response.
+	Response any
+}
+
+func (e *ScriptResponseTypeError) Error() string {
+	return fmt.Sprintf("scripted response of type %T is not a response of %s", e.Response, e.Method)
+}
+
+// Stub is an offline, deterministic implementation of sdk.API which returns canned responses.
+// Unlike a recording, it needs no prior interaction with the real network.
+// Calls can be scripted with On, such as to fail the Nth call of a method. Stubs are safe for concurrent use.
+// Create one with StubClient.
+type Stub struct {
+	config StubConfig
+
+	mu sync.Mutex
+	// The number of calls made to each method, and the scripts of each method, by call number; 0 scripts every call.
+	calls   map[string]int
+	scripts map[string]map[int]Script
+}
+
+var _ sdk.API = (*Stub)(nil)
+
+// StubClient creates a stub client which answers every call according to config, without network access.
+func StubClient(config StubConfig) *Stub {
+	if config.ChatReply == nil {
+		config.ChatReply = echoLastMessage
+	}
+	if config.EmbeddingDimensions <= 0 {
+		config.EmbeddingDimensions = DefaultEmbeddingDimensions
+	}
+	if config.ImageUrl == "" {
+		config.ImageUrl = DefaultImageUrl
+	}
+	if config.Transcript == "" {
+		config.Transcript = DefaultTranscript
+	}
+
+	return &Stub{
+		config:  config,
+		calls:   map[string]int{},
+		scripts: map[string]map[int]Script{},
+	}
+}
+
+// On scripts the nth call, starting at 1, to the given method, such as "ChatComplete", replacing any script of that call.
+// If n is 0 or less, the script applies to every call of the method which has no script of its own.
+func (s *Stub) On(method string, n int, script Script) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.scripts[method] == nil {
+		s.scripts[method] = map[int]Script{}
+	}
+	s.scripts[method][max(n, 0)] = script
+}
+
+// Calls returns the number of calls made so far to the given method, such as "ChatComplete", including failed ones.
+func (s *Stub) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// Counts a call to the given method, and waits for the delay of its script, if any.
+// It returns the script of the call, or nil if it has none, or an error if the call must fail without a response.
+func (s *Stub) begin(ctx context.Context, method string) (*Script, error) {
+	s.mu.Lock()
+	s.calls[method]++
+	script, ok := s.scripts[method][s.calls[method]]
+	if !ok {
+		script, ok = s.scripts[method][0]
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	if script.Delay > 0 {
+		timer := time.NewTimer(script.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if script.Err != nil && (method != "ChatCompleteStream" || script.PartialTokens <= 0) {
+		return nil, script.Err
+	}
+	return &script, nil
+}
+
+// Returns the scripted response of a unary call, or nil if the call has no scripted response.
+func scriptedResponse[T any](method string, script *Script) (*T, error) {
+	if script == nil || script.Response == nil {
+		return nil, nil
+	}
+
+	res, ok := script.Response.(*T)
+	if !ok {
+		return nil, &ScriptResponseTypeError{Method: method, Response: script.Response}
+	}
+	return res, nil
+}
+
+// Returns the content of the last message.
+func echoLastMessage(messages []*apigatewayv1.ChatCompleteMessage) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].GetContent()
+}
+
+// ChatComplete returns the configured chat reply as an assistant message.
+// The token count is the number of words in the reply.
+func (s *Stub) ChatComplete(ctx context.Context, request *apigatewayv1.ChatCompleteRequest, opts ...sdk.CallOption) (*apigatewayv1.ChatCompleteResponse, error) {
+	if request == nil {
+		return nil, sdk.NilRequestError
+	}
+	script, err := s.begin(ctx, "ChatComplete")
+	if err != nil {
+		return nil, err
+	}
+	if res, err := scriptedResponse[apigatewayv1.ChatCompleteResponse]("ChatComplete", script); res != nil || err != nil {
+		return res, err
+	}
+
+	reply := s.config.ChatReply(request.Message)
+	return &apigatewayv1.ChatCompleteResponse{
+		Response:   &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: reply},
+		TokenCount: int32(len(strings.Fields(reply))),
+	}, nil
+}
+
+// ChatCompleteStream streams the configured chat reply as an assistant message, one word at a time.
+// A scripted stream fails once its tokens are read if Script.PartialTokens is set, as if it had been cut off.
+func (s *Stub) ChatCompleteStream(ctx context.Context, request *apigatewayv1.ChatCompleteStreamRequest, opts ...sdk.CallOption) (*sdk.ChatCompleteStreamResponse, error) {
+	if request == nil {
+		return nil, sdk.NilRequestError
+	}
+	script, err := s.begin(ctx, "ChatCompleteStream")
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := splitWords(s.config.ChatReply(request.Message))
+	var streamErr error
+	if script != nil && script.Response != nil {
+		var ok bool
+		if tokens, ok = script.Response.([]string); !ok {
+			return nil, &ScriptResponseTypeError{Method: "ChatCompleteStream", Response: script.Response}
+		}
+	}
+	if script != nil && script.PartialTokens > 0 {
+		tokens = tokens[:min(script.PartialTokens, len(tokens))]
+		streamErr = script.Err
+		if streamErr == nil {
+			streamErr = sdk.TruncatedStreamResponseError
+		}
+	}
+	return sdk.StaticChatCompleteStream("assistant", tokens, streamErr), nil
+}
+
+// Splits text into words, keeping the whitespace before each word so that the words concatenate back to the original text.
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// Embed returns a deterministic embedding derived from a hash of the input, with values between -1 and 1.
+func (s *Stub) Embed(ctx context.Context, request *apigatewayv1.EmbedRequest, opts ...sdk.CallOption) (*apigatewayv1.EmbedResponse, error) {
+	if request == nil {
+		return nil, sdk.NilRequestError
+	}
+	script, err := s.begin(ctx, "Embed")
+	if err != nil {
+		return nil, err
+	}
+	if res, err := scriptedResponse[apigatewayv1.EmbedResponse]("Embed", script); res != nil || err != nil {
+		return res, err
+	}
+
+	tokens := int32(len(strings.Fields(request.Input)))
+	return &apigatewayv1.EmbedResponse{
+		Object: "list",
+		Model:  request.Model,
+		Data: []*apigatewayv1.EmbedResponse_Data{{
+			Object:    "embedding",
+			Embedding: hashEmbedding(request.Input, s.config.EmbeddingDimensions),
+		}},
+		Usage: &apigatewayv1.EmbedResponse_Usage{PromptTokens: tokens, TotalTokens: tokens},
+	}, nil
+}
+
+// Derives a vector with values between -1 and 1 from a hash of the input.
+func hashEmbedding(input string, dimensions int) []float32 {
+	embedding := make([]float32, dimensions)
+	for i := range embedding {
+		hash := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		hash = sha256.Sum256(append(hash[:], input...))
+		embedding[i] = float32(binary.BigEndian.Uint32(hash[:4]))/float32(1<<31) - 1
+	}
+	return embedding
+}
+
+// TextToImage returns the configured image URL, once per requested image.
+func (s *Stub) TextToImage(ctx context.Context, request *apigatewayv1.TextToImageRequest, opts ...sdk.CallOption) (*apigatewayv1.TextToImageResponse, error) {
+	if request == nil {
+		return nil, sdk.NilRequestError
+	}
+	script, err := s.begin(ctx, "TextToImage")
+	if err != nil {
+		return nil, err
+	}
+	if res, err := scriptedResponse[apigatewayv1.TextToImageResponse]("TextToImage", script); res != nil || err != nil {
+		return res, err
+	}
+
+	count := max(int(request.Count), 1)
+	images := make([]*apigatewayv1.TextToImageResponse_Image, count)
+	for i := range images {
+		images[i] = &apigatewayv1.TextToImageResponse_Image{Url: s.config.ImageUrl}
+	}
+	return &apigatewayv1.TextToImageResponse{Images: images}, nil
+}
+
+// Transcribe returns the configured transcript, with each word timed to last half a second.
+func (s *Stub) Transcribe(ctx context.Context, request *apigatewayv1.TranscribeRequest, opts ...sdk.CallOption) (*apigatewayv1.TranscribeResponse, error) {
+	if request == nil {
+		return nil, sdk.NilRequestError
+	}
+	script, err := s.begin(ctx, "Transcribe")
+	if err != nil {
+		return nil, err
+	}
+	if res, err := scriptedResponse[apigatewayv1.TranscribeResponse]("Transcribe", script); res != nil || err != nil {
+		return res, err
+	}
+
+	fields := strings.Fields(s.config.Transcript)
+	words := make([]*apigatewayv1.TranscribeResponse_Word, len(fields))
+	for i, field := range fields {
+		words[i] = &apigatewayv1.TranscribeResponse_Word{
+			Word:        field,
+			StartSecond: float64(i) * 0.5,
+			EndSecond:   float64(i+1) * 0.5,
+		}
+	}
+	return &apigatewayv1.TranscribeResponse{
+		Text:      s.config.Transcript,
+		WordCount: int32(len(words)),
+		Words:     words,
+	}, nil
+}
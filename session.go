@@ -0,0 +1,322 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// HistoryStrategy is how a ChatSession keeps the conversation within the context window of its model.
+// Strategies other than HistoryKeepAll work in whole turns, a user message along with its reply, so that a reply is never sent without
+// the message it answers.
+type HistoryStrategy int
+
+const (
+	// HistoryKeepAll sends the whole history with every request, regardless of its length.
+	// This is the default.
+	HistoryKeepAll HistoryStrategy = iota
+
+	// HistorySlidingWindow only sends the most recent turns which fit within the token budget.
+	// Older turns are kept in the history, but are no longer sent.
+	HistorySlidingWindow
+
+	// HistoryDropOldest removes the oldest turns from the history until it fits within the token budget.
+	HistoryDropOldest
+
+	// HistorySummarize replaces older turns with a summary once the history exceeds the token budget, at the cost of an extra
+	// ChatComplete call. The most recent turns which fit within half of the budget are kept as-is, and the others, including
+	// any previous summary, are summarized into a system message at the start of the history.
+	HistorySummarize
+)
+
+// DefaultSummaryPrompt is the system prompt of the calls which summarize older turns for HistorySummarize.
+const DefaultSummaryPrompt = "Summarize the following conversation in a few sentences. " +
+	"Keep any facts, names, decisions and open questions needed to continue it, and leave out small talk."
+
+// SummaryPrefix starts the content of the system message which holds the summary of older turns, for HistorySummarize.
+const SummaryPrefix = "Summary of the earlier conversation: "
+
+// ChatSessionOptions configures a ChatSession.
+type ChatSessionOptions struct {
+	// Model is the model to chat with.
+	Model string
+
+	// SystemPrompt, if set, is sent as a system message before the history with every request.
+	SystemPrompt string
+
+	// History is the conversation so far, such as one restored from storage, excluding the system prompt.
+	History []Message
+
+	// CallOptions are applied to every call of the session, before the options passed to Send or SendStream.
+	CallOptions []CallOption
+
+	// HistoryStrategy is how the history is kept within MaxContextTokens. If unspecified, it defaults to HistoryKeepAll.
+	HistoryStrategy HistoryStrategy
+
+	// MaxContextTokens is the token budget of each request, including the system prompt and the new user message.
+	// If unspecified, the maximum input tokens of the model are used, if the client reports them with ContextWindow,
+	// as *Client does for models in ClientOptions.ModelLimits; otherwise the history is never shortened.
+	MaxContextTokens int
+
+	// CountTokens counts the tokens in the content of a message.
+	// If nil, EstimateTokens is used.
+	CountTokens func(string) int
+
+	// SummaryModel is the model which summarizes older turns for HistorySummarize. If unspecified, Model is used.
+	SummaryModel string
+
+	// SummaryPrompt is the system prompt of the calls which summarize older turns. If unspecified, DefaultSummaryPrompt is used.
+	SummaryPrompt string
+}
+
+// ChatSession is a conversation with a model, which keeps the history of the conversation,
+// and sends it along with each new user message. Create one with NewChatSession.
+//
+// A message and its reply are only added to the history once the reply has been received in full, and the history is only
+// shortened by HistoryDropOldest or HistorySummarize then, so a failed send can be retried as-is. Sends should not overlap, as each one builds on the history left by the previous one.
+type ChatSession struct {
+	client  API
+	options ChatSessionOptions
+
+	mu      sync.Mutex
+	history []Message
+
+	// version counts the changes to the start of the history, so that a shortening computed on an older history is not applied.
+	version int
+}
+
+// historyUpdate is how a send shortens the history, for HistoryDropOldest and HistorySummarize, once its reply is received.
+type historyUpdate struct {
+	// version is the version of the history the update was computed on.
+	version int
+
+	// drop is the number of oldest messages to remove from the history.
+	drop int
+
+	// summary, if set, replaces the removed messages.
+	summary *Message
+}
+
+// NewChatSession creates a session of a conversation with the client, such as a *Client or an sdktest stub.
+func NewChatSession(client API, options ChatSessionOptions) *ChatSession {
+	return &ChatSession{
+		client:  client,
+		options: options,
+		history: slices.Clone(options.History),
+	}
+}
+
+// History returns the messages of the conversation so far, in order, excluding the system prompt.
+func (s *ChatSession) History() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.history)
+}
+
+// Reset forgets the history of the conversation, to start a new one with the same options.
+func (s *ChatSession) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = nil
+	s.version++
+}
+
+// Returns the messages of a request which continues the conversation with the given user message,
+// after shortening the history to fit within the token budget according to the history strategy,
+// along with the update to apply to the history once the reply is received.
+func (s *ChatSession) messages(ctx context.Context, text string, opts []CallOption) ([]*apigatewayv1.ChatCompleteMessage, historyUpdate, error) {
+	var messages []Message
+	if s.options.SystemPrompt != "" {
+		messages = append(messages, SystemMessage(s.options.SystemPrompt))
+	}
+	user := UserMessage(text)
+
+	s.mu.Lock()
+	history := slices.Clone(s.history)
+	update := historyUpdate{version: s.version}
+	s.mu.Unlock()
+
+	if budget := s.budget(ctx); budget > 0 && s.options.HistoryStrategy != HistoryKeepAll {
+		available := budget - s.countTokens(append(messages, user))
+		start := s.fit(history, available)
+
+		switch s.options.HistoryStrategy {
+		case HistorySlidingWindow:
+			history = history[start:]
+		case HistoryDropOldest:
+			history = history[start:]
+			update.drop = start
+		case HistorySummarize:
+			if start > 0 {
+				keep := s.fit(history, available/2)
+				summary, err := s.summarize(ctx, history[:keep], opts)
+				if err != nil {
+					return nil, update, err
+				}
+				history = append([]Message{summary}, history[keep:]...)
+				update.drop, update.summary = keep, &summary
+			}
+		}
+	}
+
+	messages = append(messages, history...)
+	return Messages(append(messages, user)...), update, nil
+}
+
+// Returns the token budget of each request, or 0 if it is unknown.
+func (s *ChatSession) budget(ctx context.Context) int {
+	if s.options.MaxContextTokens > 0 {
+		return s.options.MaxContextTokens
+	}
+
+	client, ok := s.client.(interface {
+		ContextWindow(ctx context.Context, model string) (maxInput int, maxOutput int, err error)
+	})
+	if !ok {
+		return 0
+	}
+	maxInput, _, err := client.ContextWindow(ctx, s.options.Model)
+	if err != nil {
+		return 0
+	}
+	return maxInput
+}
+
+// Returns the total token count of the contents of messages.
+func (s *ChatSession) countTokens(messages []Message) int {
+	countFn := s.options.CountTokens
+	if countFn == nil {
+		countFn = EstimateTokens
+	}
+
+	tokens := 0
+	for _, message := range messages {
+		tokens += countFn(message.Content)
+	}
+	return tokens
+}
+
+// Returns the index of the oldest turn from which the history fits within the given number of tokens,
+// or the length of the history if not even the most recent turn fits.
+// Turns start at the first message, and at every later user message.
+func (s *ChatSession) fit(history []Message, tokens int) int {
+	start := len(history)
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += s.countTokens(history[i : i+1])
+		if used > tokens {
+			break
+		}
+		if i == 0 || history[i].Role == RoleUser {
+			start = i
+		}
+	}
+	return start
+}
+
+// Summarizes messages with a ChatComplete call, into a system message which replaces them in the history.
+// opts are the options of the send which needs the summary.
+func (s *ChatSession) summarize(ctx context.Context, messages []Message, opts []CallOption) (Message, error) {
+	model := s.options.SummaryModel
+	if model == "" {
+		model = s.options.Model
+	}
+	prompt := s.options.SummaryPrompt
+	if prompt == "" {
+		prompt = DefaultSummaryPrompt
+	}
+
+	var transcript strings.Builder
+	for _, message := range messages {
+		if message.Role == RoleSystem {
+			// A previous summary.
+			transcript.WriteString(strings.TrimPrefix(message.Content, SummaryPrefix))
+		} else {
+			transcript.WriteString(string(message.Role) + ": " + message.Content)
+		}
+		transcript.WriteString("\n\n")
+	}
+
+	res, err := s.client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{
+		Model:   model,
+		Message: Messages(SystemMessage(prompt), UserMessage(transcript.String())),
+	}, slices.Concat(s.options.CallOptions, opts)...)
+	if err != nil {
+		return Message{}, err
+	}
+	return SystemMessage(SummaryPrefix + res.GetResponse().GetContent()), nil
+}
+
+// Adds a user message and its reply to the history, after shortening it according to update.
+// The update is skipped if the start of the history changed since it was computed, such as by another send,
+// in which case the next send shortens the history again as needed.
+func (s *ChatSession) record(text string, reply Message, update historyUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if update.drop > 0 && update.version == s.version {
+		s.history = s.history[update.drop:]
+		if update.summary != nil {
+			s.history = append([]Message{*update.summary}, s.history...)
+		}
+		s.version++
+	}
+	s.history = append(s.history, UserMessage(text), reply)
+}
+
+// Send sends a user message with the conversation so far, and returns the content of the reply.
+// Both are added to the history once the reply is received. opts also apply to the call which summarizes older turns, if any.
+func (s *ChatSession) Send(ctx context.Context, text string, opts ...CallOption) (string, error) {
+	messages, update, err := s.messages(ctx, text, opts)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := s.client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{
+		Model:   s.options.Model,
+		Message: messages,
+	}, slices.Concat(s.options.CallOptions, opts)...)
+	if err != nil {
+		return "", err
+	}
+
+	reply := MessageFromProto(res.GetResponse())
+	s.record(text, reply, update)
+	return reply.Content, nil
+}
+
+// SendStream is like Send, but streams the reply.
+// The user message and the reply are added to the history once the stream has been read to its end without an error;
+// if the stream fails or is closed early, the history is left unchanged.
+func (s *ChatSession) SendStream(ctx context.Context, text string, opts ...CallOption) (*ChatCompleteStreamResponse, error) {
+	messages, update, err := s.messages(ctx, text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.ChatCompleteStream(ctx, &apigatewayv1.ChatCompleteStreamRequest{
+		Model:   s.options.Model,
+		Message: messages,
+	}, slices.Concat(s.options.CallOptions, opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := res.TokenStream
+	var content strings.Builder
+	transformer := stream.transformer
+	stream.transformer = func(chunk *apigatewayv1.ChatCompleteStreamResponse) string {
+		token := transformer(chunk)
+		content.WriteString(token)
+		return token
+	}
+	onComplete := stream.onComplete
+	stream.onComplete = func() {
+		if onComplete != nil {
+			onComplete()
+		}
+		s.record(text, Message{Role: Role(res.Role), Content: content.String()}, update)
+	}
+	return res, nil
+}
@@ -0,0 +1,140 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Modality is the kind of input and output a model works with, which determines the method used to call it.
+type Modality string
+
+const (
+	// ModalityChat is for models called with ChatComplete or ChatCompleteStream.
+	ModalityChat Modality = "chat"
+
+	// ModalityEmbed is for models called with Embed.
+	ModalityEmbed Modality = "embed"
+
+	// ModalityImage is for models called with TextToImage.
+	ModalityImage Modality = "image"
+
+	// ModalityTranscribe is for models called with Transcribe.
+	ModalityTranscribe Modality = "transcribe"
+)
+
+// MissingSmokeTestAudioError is returned by SmokeTest for ModalityTranscribe when no audio URL was given in SmokeTestOptions.AudioUrl.
+var MissingSmokeTestAudioError = errors.New("transcription smoke tests require an audio URL")
+
+// EmptySmokeTestResponseError is returned by SmokeTest when the model served the request, but with an empty output,
+// such as no images, an empty embedding, or an empty chat reply or transcription.
+var EmptySmokeTestResponseError = errors.New("the model returned an empty response")
+
+// The maximum length of SmokeResult.Sample, in bytes.
+const smokeSampleLength = 200
+
+// SmokeResult is the outcome of SmokeTest.
+type SmokeResult struct {
+	// Model is the model that was tested.
+	Model string
+
+	// Modality is the modality the model was tested with.
+	Modality Modality
+
+	// Success is whether the model served the request.
+	Success bool
+
+	// Latency is the duration of the request, whether it succeeded or not.
+	Latency time.Duration
+
+	// Sample is a short description of the output, such as the start of a chat reply, the number of embedding dimensions,
+	// or an image URL, for checking that the output looks sensible. It is empty if the request failed.
+	Sample string
+}
+
+// SmokeTestOptions configures SmokeTest.
+type SmokeTestOptions struct {
+	// AudioUrl is the URL of the audio to transcribe for ModalityTranscribe, for which it is required.
+	// The audio should be a short clip, such as a second of speech, to keep the test cheap.
+	AudioUrl string
+}
+
+// SmokeTest checks that a model is serving correctly, by sending it a tiny fixed request appropriate to its modality,
+// and reports whether it succeeded, how long it took, and a sample of the output. This is intended for diagnostics,
+// such as verifying a model after a deployment. The request is kept as small as possible, but it is a real request
+// which consumes a minimal amount of quota: a one-word chat prompt, a one-word embedding input, a single standard quality image
+// at the smallest common size, or the transcription of options.AudioUrl, which is required for ModalityTranscribe. options may be nil
+// for other modalities.
+//
+// If the model fails, or serves the request with an empty output, the result is returned along with the error, with Success set to false;
+// an empty output fails with EmptySmokeTestResponseError.
+// If the smoke test cannot be run at all, such as for an unknown modality, only an error is returned.
+func (c *Client) SmokeTest(ctx context.Context, model string, modality Modality, options *SmokeTestOptions) (*SmokeResult, error) {
+	if options == nil {
+		options = &SmokeTestOptions{}
+	}
+
+	var call func() (string, error)
+	switch modality {
+	case ModalityChat:
+		call = func() (string, error) {
+			res, err := c.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{
+				Model:   model,
+				Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Reply with OK."}},
+			})
+			return res.GetResponse().GetContent(), err
+		}
+	case ModalityEmbed:
+		call = func() (string, error) {
+			res, err := c.Embed(ctx, &apigatewayv1.EmbedRequest{Model: model, Input: "ping"})
+			if err != nil || len(firstEmbedding(res)) == 0 {
+				return "", err
+			}
+			return fmt.Sprintf("%d dimensions", len(firstEmbedding(res))), nil
+		}
+	case ModalityImage:
+		call = func() (string, error) {
+			res, err := c.TextToImage(ctx, &apigatewayv1.TextToImageRequest{
+				Model:   model,
+				Prompt:  "A red dot",
+				Count:   1,
+				Quality: apigatewayv1.ImageQuality_IMAGE_QUALITY_STANDARD,
+				Size:    "256x256",
+			})
+			if err != nil || len(res.Images) == 0 {
+				return "", err
+			}
+			return res.Images[0].Url, nil
+		}
+	case ModalityTranscribe:
+		if options.AudioUrl == "" {
+			return nil, c.methodError("SmokeTest", MissingSmokeTestAudioError)
+		}
+		call = func() (string, error) {
+			res, err := c.Transcribe(ctx, &apigatewayv1.TranscribeRequest{Model: model, Url: options.AudioUrl})
+			return res.GetText(), err
+		}
+	default:
+		return nil, c.methodError("SmokeTest", fmt.Errorf("unknown modality %q", modality))
+	}
+
+	start := time.Now()
+	sample, err := call()
+	if err == nil && sample == "" {
+		err = c.methodError("SmokeTest", EmptySmokeTestResponseError)
+	}
+	result := &SmokeResult{
+		Model:    model,
+		Modality: modality,
+		Success:  err == nil,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		return result, err
+	}
+
+	result.Sample = truncateUtf8(sample, smokeSampleLength)
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"errors"
+	"io"
+	"strings"
+)
+
+// SectionToken is a piece of text read from a SectionStream, tagged with the index of the section it belongs to.
+type SectionToken struct {
+	// Section is the zero-based index of the section, which is the number of delimiters that preceded the text.
+	Section int
+
+	// Text is the token text, without any part of the delimiter.
+	Text string
+}
+
+// SectionStream splits a token stream into sections separated by a delimiter, such as when a model emits its reasoning,
+// followed by a delimiter, followed by its final answer.
+// Create one with SplitTokenStream.
+type SectionStream struct {
+	stream    *ResponseStream[apigatewayv1.ChatCompleteStreamResponse, string]
+	delimiter string
+
+	// Text read from the stream that has not been emitted yet, because it may be the start of a delimiter.
+	pending string
+	section int
+
+	// Tokens ready to be returned by Read.
+	queue []SectionToken
+
+	// The error that ended the underlying stream, returned once the queue is drained.
+	err error
+}
+
+// SplitTokenStream splits the token stream of a chat completion stream at every occurrence of delimiter.
+// Reading from the returned stream yields the tokens of the original stream, tagged with their section, with the delimiters removed.
+// A delimiter that is split across several tokens is still recognized. To do so, text that may be the start of a delimiter
+// is held back until enough of the stream was read to rule it out.
+//
+// Tokens are never empty, so a section with no content, such as one between two consecutive delimiters, yields no tokens.
+// Once the underlying stream ends, Read yields io.EOF, or the error that ended the stream, after all remaining text has been returned.
+// If delimiter is empty, the stream is not split, and every token belongs to section 0.
+func SplitTokenStream(res *ChatCompleteStreamResponse, delimiter string) *SectionStream {
+	return &SectionStream{
+		stream:    res.TokenStream,
+		delimiter: delimiter,
+	}
+}
+
+// Read returns the next token and the section it belongs to.
+// If the stream is complete, the error will be io.EOF.
+func (s *SectionStream) Read() (SectionToken, error) {
+	for len(s.queue) == 0 {
+		if s.err != nil {
+			return SectionToken{}, s.err
+		}
+		s.fill()
+	}
+
+	token := s.queue[0]
+	s.queue = s.queue[1:]
+	return token, nil
+}
+
+// Reads a token from the underlying stream, and queues the text that can be attributed to a section.
+func (s *SectionStream) fill() {
+	token, err := s.stream.Read()
+	if err != nil {
+		// The stream is over, so whatever was held back cannot be the start of a delimiter.
+		s.emit(s.pending)
+		s.pending = ""
+		s.err = err
+		return
+	}
+	if s.delimiter == "" {
+		s.emit(token)
+		return
+	}
+
+	s.pending += token
+	for {
+		before, after, found := strings.Cut(s.pending, s.delimiter)
+		if !found {
+			break
+		}
+		s.emit(before)
+		s.section++
+		s.pending = after
+	}
+
+	held := partialDelimiterLength(s.pending, s.delimiter)
+	s.emit(s.pending[:len(s.pending)-held])
+	s.pending = s.pending[len(s.pending)-held:]
+}
+
+// Queues text in the current section, unless it is empty.
+func (s *SectionStream) emit(text string) {
+	if text != "" {
+		s.queue = append(s.queue, SectionToken{Section: s.section, Text: text})
+	}
+}
+
+// Returns the length of the longest suffix of text which is a proper prefix of delimiter.
+func partialDelimiterLength(text string, delimiter string) int {
+	for n := min(len(text), len(delimiter)-1); n > 0; n-- {
+		if strings.HasSuffix(text, delimiter[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// Sections reads the rest of the stream and returns the concatenated text of each section, in order.
+// Sections with no content are included as empty strings, as long as a later section has content.
+// If the stream fails, the sections read so far are returned along with the error.
+func (s *SectionStream) Sections() ([]string, error) {
+	var sections []string
+	for {
+		token, err := s.Read()
+		if errors.Is(err, io.EOF) {
+			return sections, nil
+		}
+		if err != nil {
+			return sections, err
+		}
+
+		for len(sections) <= token.Section {
+			sections = append(sections, "")
+		}
+		sections[token.Section] += token.Text
+	}
+}
+
+// Close closes the underlying stream.
+// Any text that has not been read yet is discarded, and subsequent calls to Read will yield io.EOF.
+func (s *SectionStream) Close() error {
+	s.queue = nil
+	s.pending = ""
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	return s.stream.Close()
+}
@@ -0,0 +1,125 @@
+package function_go_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SSEHeartbeat is the comment written by StreamSSE to keep idle connections alive.
+// Comments are ignored by EventSource, so heartbeats never show up as content.
+const SSEHeartbeat = ": ping\n\n"
+
+// SSEOptions configures StreamSSE.
+type SSEOptions struct {
+	// HeartbeatInterval is how long to wait for a token before writing SSEHeartbeat, which keeps proxies and browsers from
+	// closing connections that are idle during long gaps between tokens, such as while a model is reasoning.
+	// Heartbeats are only written while no token arrives within the interval, so they stop as soon as tokens flow again.
+	// If unspecified, no heartbeats are written.
+	HeartbeatInterval time.Duration
+}
+
+// StreamSSE writes the tokens of a chat completion stream to w as server-sent events, such as for relaying a response to a browser.
+// Each token is written as a "token" event as soon as it is read, and flushed if w supports it. Once the stream ends,
+// a "done" event with no data is written. If the stream fails, an "error" event holding the error message is written,
+// and the error is returned. Response headers for an event stream are set before the first write.
+//
+// The stream is always closed before StreamSSE returns. If ctx is done first, such as because the browser disconnected,
+// StreamSSE returns the context error right away, and the stream is closed once its pending read returns, so the stream should
+// be opened with a context that is canceled in that case, such as the HTTP request context.
+func StreamSSE(ctx context.Context, w http.ResponseWriter, res *ChatCompleteStreamResponse, options SSEOptions) error {
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	type result struct {
+		token string
+		err   error
+	}
+
+	// Tokens are read in the background, so that heartbeats can be written while a read is blocked.
+	// The reader owns the stream, and closes it once it stops reading.
+	results := make(chan result)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer res.TokenStream.Close()
+		for {
+			token, err := res.TokenStream.Read()
+			select {
+			case results <- result{token, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	var timer *time.Timer
+	if options.HeartbeatInterval > 0 {
+		timer = time.NewTimer(options.HeartbeatInterval)
+		defer timer.Stop()
+		heartbeat = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-heartbeat:
+			if err := writeSSE(w, SSEHeartbeat); err != nil {
+				return err
+			}
+			timer.Reset(options.HeartbeatInterval)
+
+		case result := <-results:
+			if errors.Is(result.err, io.EOF) {
+				return writeSSE(w, sseEvent("done", ""))
+			}
+			if result.err != nil {
+				writeSSE(w, sseEvent("error", result.err.Error()))
+				return result.err
+			}
+
+			if err := writeSSE(w, sseEvent("token", result.token)); err != nil {
+				return err
+			}
+			if timer != nil {
+				timer.Reset(options.HeartbeatInterval)
+			}
+		}
+	}
+}
+
+// Formats a server-sent event. Since data cannot contain line breaks, each line of data is written as a separate data field,
+// which EventSource joins back together with line breaks.
+func sseEvent(name string, data string) string {
+	var event strings.Builder
+	fmt.Fprintf(&event, "event: %s\n", name)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&event, "data: %s\n", line)
+	}
+	event.WriteString("\n")
+	return event.String()
+}
+
+// Writes text to w, and flushes it if w supports flushing.
+func writeSSE(w http.ResponseWriter, text string) error {
+	if _, err := io.WriteString(w, text); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
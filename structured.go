@@ -0,0 +1,323 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JsonAttempts is the number of times ChatCompleteJson asks for a reply, until one is valid.
+const JsonAttempts = 3
+
+// InvalidJsonResponseError is returned by ChatCompleteJson when no reply was valid JSON matching the schema of the result type.
+type InvalidJsonResponseError struct {
+	// Content is the content of the last reply.
+	Content string
+
+	// Err is why the last reply was rejected.
+	Err error
+}
+
+func (e *InvalidJsonResponseError) Error() string {
+	return fmt.Sprintf("reply is not valid JSON for the requested schema: %v", e.Err)
+}
+
+func (e *InvalidJsonResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ChatCompleteJson completes a chat with a reply in JSON, which is unmarshaled into a value of type T.
+//
+// The gateway has no JSON mode, so the request is sent with an extra system message which asks for a JSON reply matching the
+// schema of T, as derived by JsonSchemaOf. A Markdown code fence around the JSON value is ignored, as is any other text around
+// a JSON object or array.
+// A reply which is not valid JSON, or does not match the schema, is sent back to the model along with the reason it was rejected,
+// to ask for a corrected reply, up to JsonAttempts replies in total. If none is valid, an *InvalidJsonResponseError is returned.
+//
+// Errors are wrapped in a *MethodError for "ChatCompleteJson", unless they come from the client.
+func ChatCompleteJson[T any](ctx context.Context, client API, request *apigatewayv1.ChatCompleteRequest, opts ...CallOption) (T, error) {
+	var result T
+	if request == nil {
+		return result, wrapMethodError("ChatCompleteJson", NilRequestError)
+	}
+
+	schema := JsonSchemaOf[T]()
+	schemaJson, err := json.Marshal(schema)
+	if err != nil {
+		return result, wrapMethodError("ChatCompleteJson", err)
+	}
+	instructions := SystemMessage("Reply with only a JSON value, without any explanation, which matches this JSON schema:\n" + string(schemaJson))
+	messages := append([]*apigatewayv1.ChatCompleteMessage{instructions.Proto()}, request.GetMessage()...)
+
+	var invalid *InvalidJsonResponseError
+	for range JsonAttempts {
+		res, err := client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{Model: request.GetModel(), Message: messages}, opts...)
+		if err != nil {
+			return result, err
+		}
+
+		content := res.GetResponse().GetContent()
+		var value T
+		err = decodeJson(content, schema, &value)
+		if err == nil {
+			return value, nil
+		}
+		invalid = &InvalidJsonResponseError{Content: content, Err: err}
+
+		messages = append(messages,
+			AssistantMessage(content).Proto(),
+			UserMessage(fmt.Sprintf("That reply is invalid: %v. Reply again with only the corrected JSON value.", invalid.Err)).Proto(),
+		)
+	}
+	return result, wrapMethodError("ChatCompleteJson", invalid)
+}
+
+// Decodes the JSON value within content into result, after validating it against the schema.
+// If the schema allows an object or an array and content holds one, any text around it is ignored;
+// otherwise content, without any code fence, must be the JSON value, such as for a scalar.
+func decodeJson(content string, schema map[string]any, result any) error {
+	content = stripCodeFence(content)
+	data := []byte(content)
+	if allowsContainer(schema) {
+		start := strings.IndexAny(content, "{[")
+		end := strings.LastIndexAny(content, "}]")
+		if start >= 0 && end > start {
+			data = data[start : end+1]
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if err := validateJson(value, schema, "$"); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// Returns the content of the first Markdown code fence in content, without its language tag, or content itself if it has none.
+// Surrounding whitespace is trimmed.
+func stripCodeFence(content string) string {
+	_, body, ok := strings.Cut(content, "```")
+	if !ok {
+		return strings.TrimSpace(content)
+	}
+	body, _, _ = strings.Cut(body, "```")
+	if _, code, ok := strings.Cut(body, "\n"); ok {
+		body = code
+	}
+	return strings.TrimSpace(body)
+}
+
+// Reports whether a schema returned by JsonSchemaOf allows an object or an array.
+func allowsContainer(schema map[string]any) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == "object" || t == "array"
+	case []string:
+		return slices.Contains(t, "object") || slices.Contains(t, "array")
+	default:
+		// Any value is allowed.
+		return true
+	}
+}
+
+// JsonSchemaOf returns the JSON schema of the values of type T, as they are marshaled by encoding/json, such as to describe
+// the reply expected from a model. Struct fields are named after their json tag, and are required unless they are tagged omitempty;
+// a description tag on a field, such as `description:"The city name"`, describes it in the schema.
+// Pointers, slices, maps and interfaces may also be null. Recursive types are described down to their first repetition,
+// below which any value is allowed.
+func JsonSchemaOf[T any]() map[string]any {
+	return jsonSchema(reflect.TypeFor[T](), nil)
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// Returns the JSON schema of the values of type t. seen holds the struct types being described, to stop at recursive types.
+func jsonSchema(t reflect.Type, seen []reflect.Type) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+
+	var schema map[string]any
+	switch {
+	case t == timeType:
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom marshaling may produce any value.
+		return map[string]any{}
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			schema = map[string]any{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			schema = map[string]any{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			schema = map[string]any{"type": "number"}
+		case reflect.String:
+			schema = map[string]any{"type": "string"}
+		case reflect.Slice, reflect.Array:
+			if t.Elem().Kind() == reflect.Uint8 {
+				// Byte slices are marshaled as base64 strings.
+				schema = map[string]any{"type": "string"}
+			} else {
+				schema = map[string]any{"type": "array", "items": jsonSchema(t.Elem(), seen)}
+			}
+			nullable = nullable || t.Kind() == reflect.Slice
+		case reflect.Map:
+			schema = map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem(), seen)}
+			nullable = true
+		case reflect.Struct:
+			if slices.Contains(seen, t) {
+				return map[string]any{}
+			}
+			schema = structSchema(t, append(seen, t))
+		default:
+			// Interfaces may hold any value.
+			return map[string]any{}
+		}
+	}
+
+	if nullable {
+		schema["type"] = []string{schema["type"].(string), "null"}
+	}
+	return schema
+}
+
+// Returns the JSON schema of a struct type, with its fields as properties.
+func structSchema(t reflect.Type, seen []reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for _, field := range reflect.VisibleFields(t) {
+		if len(field.Index) > 1 {
+			continue
+		}
+
+		// Like encoding/json, embedded structs are considered even if their type is unexported, as their exported fields are promoted.
+		embeddedType := field.Type
+		if embeddedType.Kind() == reflect.Pointer {
+			embeddedType = embeddedType.Elem()
+		}
+		if !field.IsExported() && !(field.Anonymous && embeddedType.Kind() == reflect.Struct) {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		// Untagged embedded structs have their fields promoted.
+		if field.Anonymous && name == "" && embeddedType.Kind() == reflect.Struct {
+			if slices.Contains(seen, embeddedType) {
+				continue
+			}
+			embedded := structSchema(embeddedType, append(seen, embeddedType))
+			for key, value := range embedded["properties"].(map[string]any) {
+				properties[key] = value
+			}
+			required = append(required, embedded["required"].([]string)...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		property := jsonSchema(field.Type, seen)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		properties[name] = property
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	slices.Sort(required)
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+// Validates a decoded JSON value against a schema returned by JsonSchemaOf. path locates the value, for error messages.
+func validateJson(value any, schema map[string]any, path string) error {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	default:
+		// Any value is allowed.
+		return nil
+	}
+
+	actual := jsonType(value)
+	if !slices.Contains(types, actual) && !(actual == "integer" && slices.Contains(types, "number")) {
+		return fmt.Errorf("%s is %s, expected %s", path, actual, strings.Join(types, " or "))
+	}
+
+	switch value := value.(type) {
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range value {
+			if err := validateJson(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if properties, ok := schema["properties"].(map[string]any); ok {
+			for _, name := range schema["required"].([]string) {
+				if _, ok := value[name]; !ok {
+					return fmt.Errorf("%s is missing required property %q", path, name)
+				}
+			}
+			for name, property := range properties {
+				if v, ok := value[name]; ok {
+					if err := validateJson(v, property.(map[string]any), path+"."+name); err != nil {
+						return err
+					}
+				}
+			}
+		} else if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			for name, v := range value {
+				if err := validateJson(v, additional, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the JSON schema type of a value decoded with json.Decoder.UseNumber.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	var rateLimited atomic.Bool
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		if rateLimited.Load() {
+			return nil, connect.NewError(connect.CodeResourceExhausted, errors.New("rate limited"))
+		}
+		return embed(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		AdaptiveConcurrency: &sdk.AdaptiveConcurrency{MinConcurrency: 1, MaxConcurrency: 8},
+	})
+	call := func() error {
+		_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+		return err
+	}
+	expectLimit := func(expected int) {
+		t.Helper()
+		if limit, inFlight := client.Concurrency(); limit != expected || inFlight != 0 {
+			t.Fatalf("Expected a limit of %d with no calls in flight, got %d with %d in flight", expected, limit, inFlight)
+		}
+	}
+
+	expectLimit(8)
+
+	// Rate limits halve the limit, down to the minimum.
+	rateLimited.Store(true)
+	for _, expected := range []int{4, 2, 1, 1} {
+		if err := call(); connect.CodeOf(err) != connect.CodeResourceExhausted {
+			t.Fatalf("Expected a rate limit error, got %v", err)
+		}
+		expectLimit(expected)
+	}
+
+	// A run of successes as long as the limit raises it by one.
+	rateLimited.Store(false)
+	for _, expected := range []int{2, 2, 3} {
+		if err := call(); err != nil {
+			t.Fatalf("Embed failed with error %v", err)
+		}
+		expectLimit(expected)
+	}
+}
+
+func TestAdaptiveConcurrencyLimitsCallsInFlight(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return embed(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		AdaptiveConcurrency: &sdk.AdaptiveConcurrency{MaxConcurrency: 8, InitialConcurrency: 2},
+	})
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+				t.Errorf("Embed failed with error %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The limit rises to 3 after 2 successes, and to 4 after 3 more.
+	if peak.Load() > 4 {
+		t.Fatalf("Expected at most 4 calls in flight, got %d", peak.Load())
+	}
+	if limit, _ := client.Concurrency(); limit != 4 {
+		t.Fatalf("Expected the limit to rise to 4 after 6 successes, got %d", limit)
+	}
+}
+
+func TestAdaptiveConcurrencyWaitsForContext(t *testing.T) {
+	release := make(chan struct{})
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		<-release
+		return embed(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		AdaptiveConcurrency: &sdk.AdaptiveConcurrency{MaxConcurrency: 1},
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+		done <- err
+	}()
+	for {
+		if _, inFlight := client.Concurrency(); inFlight == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+	if !errors.Is(err, context.DeadlineExceeded) && connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+		t.Fatalf("Expected the waiting call to time out, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if _, inFlight := client.Concurrency(); inFlight != 0 {
+		t.Fatalf("Expected no calls in flight, got %d", inFlight)
+	}
+}
+
+func TestAdaptiveConcurrencyInvalidOptions(t *testing.T) {
+	_, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKey:              "mykey",
+		AdaptiveConcurrency: &sdk.AdaptiveConcurrency{MinConcurrency: 4, MaxConcurrency: 2},
+	})
+	if err == nil {
+		t.Fatalf("Expected NewClient to fail when the maximum concurrency is below the minimum")
+	}
+}
+
+func TestAdaptiveConcurrencyReleasesStreamsReadToEnd(t *testing.T) {
+	client := newTestClient(t, newChatGateway(), sdk.ClientOptions{
+		AdaptiveConcurrency: &sdk.AdaptiveConcurrency{MinConcurrency: 1, MaxConcurrency: 2},
+	})
+
+	// More streams than the limit, each read to its end without being closed.
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		res, err := client.ChatCompleteStream(ctx, &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+		if err != nil {
+			cancel()
+			t.Fatalf("ChatCompleteStream failed with error %v", err)
+		}
+		if _, err := res.TokenStream.ReadAll(); err != nil {
+			cancel()
+			t.Fatalf("ReadAll failed with error %v", err)
+		}
+		cancel()
+	}
+
+	if _, inFlight := client.Concurrency(); inFlight != 0 {
+		t.Fatalf("Expected no streams in flight once read to their end, got %d", inFlight)
+	}
+}
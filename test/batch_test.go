@@ -0,0 +1,109 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestCompareModels(t *testing.T) {
+	gateway := &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			if req.Msg.Model == "broken" {
+				return nil, connect.NewError(connect.CodeNotFound, errors.New("no such model"))
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: "from " + req.Msg.Model},
+			}), nil
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	responses, err := client.CompareModels(context.Background(), &apigatewayv1.ChatCompleteRequest{}, []string{"a", "broken", "b"})
+
+	var batchErr *sdk.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected BatchError, got %v", err)
+	}
+	if batchErr.Errors[0] != nil || batchErr.Errors[1] == nil || batchErr.Errors[2] != nil {
+		t.Fatalf("Expected only the second model to fail, got %v", batchErr.Errors)
+	}
+	if len(responses) != 2 || responses["a"] != "from a" || responses["b"] != "from b" {
+		t.Fatalf("Unexpected responses %v", responses)
+	}
+}
+
+func TestCompareModelsErrors(t *testing.T) {
+	calls := 0
+	gateway := &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			calls++
+			return nil, connect.NewError(connect.CodeNotFound, errors.New("no such model"))
+		},
+	}
+	var wrapped []string
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		ErrorWrapper: func(method string, err error) error {
+			wrapped = append(wrapped, method)
+			return err
+		},
+	})
+
+	_, err := client.CompareModels(context.Background(), &apigatewayv1.ChatCompleteRequest{}, []string{"a", "b", "a"})
+	var duplicateErr *sdk.DuplicateModelError
+	if !errors.As(err, &duplicateErr) || duplicateErr.Model != "a" {
+		t.Fatalf("Expected DuplicateModelError for a, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("Expected no requests to be made, got %d", calls)
+	}
+
+	wrapped = nil
+	_, err = client.CompareModels(context.Background(), &apigatewayv1.ChatCompleteRequest{}, []string{"a"})
+	var methodErr *sdk.MethodError
+	var batchErr *sdk.BatchError
+	if !errors.As(err, &methodErr) || methodErr.Method != "CompareModels" || !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError wrapped in a MethodError for CompareModels, got %v", err)
+	}
+	if len(wrapped) != 2 || wrapped[1] != "CompareModels" {
+		t.Fatalf("Expected the batch error to go through ErrorWrapper, got %v", wrapped)
+	}
+}
+
+func TestTextToImageBatch(t *testing.T) {
+	gateway := &fakeGateway{
+		textToImage: func(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+			if req.Msg.Prompt == "forbidden" {
+				return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("prompt rejected"))
+			}
+			return connect.NewResponse(&apigatewayv1.TextToImageResponse{
+				Images: []*apigatewayv1.TextToImageResponse_Image{{Url: req.Msg.Model + "/" + req.Msg.Size + "/" + req.Msg.Prompt}},
+			}), nil
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	shared := &apigatewayv1.TextToImageRequest{Model: "model", Size: "512x512", Prompt: "ignored"}
+	responses, err := client.TextToImageBatch(context.Background(), []string{"red shoe", "forbidden", "blue shoe"}, shared, 2)
+
+	var methodErr *sdk.MethodError
+	var batchErr *sdk.BatchError
+	if !errors.As(err, &methodErr) || methodErr.Method != "TextToImageBatch" || !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError wrapped in a MethodError for TextToImageBatch, got %v", err)
+	}
+	if batchErr.Errors[0] != nil || batchErr.Errors[1] == nil || batchErr.Errors[2] != nil {
+		t.Fatalf("Expected only the second prompt to fail, got %v", batchErr.Errors)
+	}
+	if len(responses) != 3 || responses[1] != nil {
+		t.Fatalf("Expected a nil response for the failed prompt, got %v", responses)
+	}
+	if responses[0].Images[0].Url != "model/512x512/red shoe" || responses[2].Images[0].Url != "model/512x512/blue shoe" {
+		t.Fatalf("Expected responses in prompt order with shared parameters, got %v", responses)
+	}
+	if shared.Prompt != "ignored" {
+		t.Fatalf("Expected the shared request not to be modified")
+	}
+}
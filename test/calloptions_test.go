@@ -0,0 +1,82 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHeaderEchoGateway creates a gateway which replies with the API key and the values of the X-Tenant header of the request.
+func newHeaderEchoGateway() *fakeGateway {
+	echo := func(header http.Header) string {
+		return strings.Join(append(header.Values("x-api-key"), header.Values("X-Tenant")...), ",")
+	}
+
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: echo(req.Header())},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", echo(req.Header()))
+		},
+	}
+}
+
+func TestCallOptionsHeaders(t *testing.T) {
+	client := newTestClient(t, newHeaderEchoGateway(), sdk.ClientOptions{ApiKey: "clientkey"})
+	request := &apigatewayv1.ChatCompleteRequest{Model: "model"}
+
+	res, err := client.ChatComplete(context.Background(), request)
+	if err != nil || res.Response.Content != "clientkey" {
+		t.Fatalf("Expected the client key without call options, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+
+	res, err = client.ChatComplete(context.Background(), request,
+		sdk.WithCallApiKey("tenantkey"),
+		sdk.WithCallHeader("X-Tenant", "a"),
+		sdk.WithCallHeader("X-Tenant", "b"),
+	)
+	if err != nil || res.Response.Content != "tenantkey,a,b" {
+		t.Fatalf("Expected the tenant key and headers, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+
+	stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"},
+		sdk.WithCallApiKey("streamkey"),
+		sdk.WithCallHeader("X-Tenant", "c"),
+	)
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	tokens, err := stream.TokenStream.ReadAll()
+	if err != nil || len(tokens) != 1 || tokens[0] != "streamkey,c" {
+		t.Fatalf("Expected the stream to use the call key and headers, got %q (error %v)", tokens, err)
+	}
+}
+
+func TestCallOptionsTimeout(t *testing.T) {
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		time.Sleep(50 * time.Millisecond)
+		return embed(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{Timeout: 10 * time.Millisecond})
+	request := &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}
+
+	if _, err := client.Embed(context.Background(), request); connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+		t.Fatalf("Expected the client timeout to apply, got %v", err)
+	}
+	if _, err := client.Embed(context.Background(), request, sdk.WithCallTimeout(time.Second)); err != nil {
+		t.Fatalf("Expected a longer call timeout to override the client timeout, got %v", err)
+	}
+	if _, err := client.Embed(context.Background(), request, sdk.WithCallTimeout(0)); err != nil {
+		t.Fatalf("Expected a zero call timeout to disable the client timeout, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+	"time"
+)
+
+func TestCancelAll(t *testing.T) {
+	started := make(chan struct{})
+	gateway := &fakeGateway{
+		embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+		done <- err
+	}()
+
+	<-started
+	client.CancelAll()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, sdk.ClientCanceledError) {
+			t.Fatalf("Expected ClientCanceledError for the in-flight request, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("In-flight request was not canceled")
+	}
+
+	_, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if !errors.Is(err, sdk.ClientCanceledError) {
+		t.Fatalf("Expected ClientCanceledError for a new stream, got %v", err)
+	}
+	_, err = client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if !errors.Is(err, sdk.ClientCanceledError) {
+		t.Fatalf("Expected ClientCanceledError for a new request, got %v", err)
+	}
+}
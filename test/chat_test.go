@@ -0,0 +1,66 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"testing"
+)
+
+func newChatGateway() *fakeGateway {
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response:   &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: "Hi there"},
+				TokenCount: 2,
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", "Hi", " there")
+		},
+	}
+}
+
+func TestChatWithoutStreaming(t *testing.T) {
+	client := newTestClient(t, newChatGateway(), sdk.ClientOptions{})
+
+	text, stream, err := client.Chat(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}, false)
+	if err != nil {
+		t.Fatalf("Chat failed with error %v", err)
+	}
+	if stream != nil {
+		t.Fatalf("Expected nil stream")
+	}
+	if text != "Hi there" {
+		t.Fatalf("Expected %q, got %q", "Hi there", text)
+	}
+}
+
+func TestChatWithStreaming(t *testing.T) {
+	client := newTestClient(t, newChatGateway(), sdk.ClientOptions{})
+
+	text, stream, err := client.Chat(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}, true)
+	if err != nil {
+		t.Fatalf("Chat failed with error %v", err)
+	}
+	if text != "" {
+		t.Fatalf("Expected empty text, got %q", text)
+	}
+
+	for {
+		token, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed with error %v", err)
+		}
+		text += token
+	}
+	if text != "Hi there" {
+		t.Fatalf("Expected %q, got %q", "Hi there", text)
+	}
+}
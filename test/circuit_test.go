@@ -0,0 +1,121 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	flaky := &flakyGateway{failures: map[string][]connect.Code{
+		"model": {connect.CodeUnavailable, connect.CodeInternal, connect.CodeUnavailable},
+	}}
+	client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+		CircuitBreaker: &sdk.CircuitBreaker{FailureThreshold: 2, Cooldown: 50 * time.Millisecond},
+	})
+	call := func() error {
+		_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+		return err
+	}
+	expectOpen := func() {
+		t.Helper()
+		calls := len(flaky.calls)
+		var openErr *sdk.CircuitOpenError
+		if err := call(); !errors.As(err, &openErr) {
+			t.Fatalf("Expected CircuitOpenError, got %v", err)
+		}
+		stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+		if !errors.As(err, &openErr) {
+			t.Fatalf("Expected CircuitOpenError for a stream, got %v (stream %v)", err, stream)
+		}
+		if len(flaky.calls) != calls {
+			t.Fatalf("Expected no request to be made while the breaker is open")
+		}
+	}
+
+	// Two consecutive failures open the breaker.
+	for i := 0; i < 2; i++ {
+		if err := call(); err == nil {
+			t.Fatalf("Expected call %d to fail", i+1)
+		}
+	}
+	expectOpen()
+
+	// After the cooldown, a failed trial call opens the breaker again.
+	time.Sleep(60 * time.Millisecond)
+	if err := call(); connect.CodeOf(err) != connect.CodeUnavailable {
+		t.Fatalf("Expected the trial call to reach the gateway and fail, got %v", err)
+	}
+	expectOpen()
+
+	// A successful trial call closes it.
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := call(); err != nil {
+			t.Fatalf("Expected call to succeed once the gateway recovered, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenStream(t *testing.T) {
+	flaky := &flakyGateway{failures: map[string][]connect.Code{
+		"model": {connect.CodeUnavailable, connect.CodeUnavailable},
+	}}
+	client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+		CircuitBreaker: &sdk.CircuitBreaker{FailureThreshold: 2, Cooldown: 50 * time.Millisecond},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err == nil {
+			t.Fatalf("Expected call %d to fail", i+1)
+		}
+	}
+
+	// The trial call is a stream, read to its end without being closed, which closes the breaker.
+	time.Sleep(60 * time.Millisecond)
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("Expected the trial stream to reach the gateway, got %v", err)
+	}
+	if _, err := res.TokenStream.ReadAll(); err != nil {
+		t.Fatalf("ReadAll failed with error %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+			t.Fatalf("Expected the breaker to be closed after the trial stream succeeded, got %v", err)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	flaky := &flakyGateway{failures: map[string][]connect.Code{
+		"model": {connect.CodeUnavailable, connect.CodeInvalidArgument, connect.CodeUnavailable, connect.CodeNotFound},
+	}}
+	client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+		CircuitBreaker: &sdk.CircuitBreaker{FailureThreshold: 2, Cooldown: time.Hour},
+	})
+
+	for i := 0; i < 5; i++ {
+		_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+		var openErr *sdk.CircuitOpenError
+		if errors.As(err, &openErr) {
+			t.Fatalf("Expected the breaker to stay closed, as failures were not consecutive")
+		}
+	}
+	if len(flaky.calls) != 5 {
+		t.Fatalf("Expected every call to reach the gateway, got %d", len(flaky.calls))
+	}
+}
+
+func TestCircuitBreakerInvalidOptions(t *testing.T) {
+	for _, options := range []sdk.CircuitBreaker{{FailureThreshold: 0, Cooldown: time.Second}, {FailureThreshold: 1}} {
+		if _, err := sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", CircuitBreaker: &options}); err == nil {
+			t.Fatalf("Expected NewClient to fail for options %+v", options)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	cases := map[sdk.Codec]string{
+		sdk.CodecProto: "application/proto",
+		sdk.CodecJson:  "application/json",
+	}
+
+	for codec, expected := range cases {
+		var contentType string
+		gateway := &fakeGateway{
+			embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+				contentType = req.Header().Get("Content-Type")
+				return connect.NewResponse(&apigatewayv1.EmbedResponse{}), nil
+			},
+		}
+		client := newTestClient(t, gateway, sdk.ClientOptions{Codec: codec})
+
+		if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+			t.Fatalf("Embed failed with error %v", err)
+		}
+		if contentType != expected {
+			t.Errorf("Expected content type %q, got %q", expected, contentType)
+		}
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	cases := []struct {
+		protocol sdk.ClientOption
+		expected string
+	}{
+		{sdk.WithConnectProtocol(), "application/proto"},
+		{sdk.WithGrpc(), "application/grpc"},
+		{sdk.WithGrpcWeb(), "application/grpc-web+proto"},
+	}
+
+	for _, c := range cases {
+		var contentType string
+		gateway := newStreamGateway("Hello", " world")
+		gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			contentType = req.Header().Get("Content-Type")
+			return connect.NewResponse(&apigatewayv1.EmbedResponse{}), nil
+		}
+
+		// gRPC requires HTTP/2, so the gateway is served over TLS.
+		mux := http.NewServeMux()
+		mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(gateway))
+		server := httptest.NewUnstartedServer(mux)
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		t.Cleanup(server.Close)
+
+		client, err := sdk.New("mykey", sdk.WithBaseUrl(server.URL), sdk.WithHttpClient(server.Client()), c.protocol)
+		if err != nil {
+			t.Fatalf("Client creation failed with error %v", err)
+		}
+
+		if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+			t.Fatalf("Embed failed with error %v", err)
+		}
+		if contentType != c.expected {
+			t.Errorf("Expected content type %q, got %q", c.expected, contentType)
+		}
+
+		stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+		if err != nil {
+			t.Fatalf("ChatCompleteStream failed with error %v", err)
+		}
+		if read, err := stream.TokenStream.ReadAll(); err != nil || strings.Join(read, "") != "Hello world" {
+			t.Fatalf("Expected the stream to be read over %s, got %q (error %v)", c.expected, read, err)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	var encoding string
+	mux := http.NewServeMux()
+	mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(newEmbedGateway()))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := sdk.New("mykey", sdk.WithBaseUrl(server.URL), sdk.WithGzip(), sdk.WithOptions(func(options *sdk.ClientOptions) {
+		options.CompressMinBytes = 1024
+	}))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: strings.Repeat("text ", 1000)}); err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if encoding != sdk.CompressionGzip {
+		t.Errorf("Expected a large request to be compressed with gzip, got encoding %q", encoding)
+	}
+
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if encoding != "" {
+		t.Errorf("Expected a small request not to be compressed, got encoding %q", encoding)
+	}
+
+	// Compressions other than gzip must be registered.
+	client, err = sdk.New("mykey", sdk.WithBaseUrl(server.URL), sdk.WithCompression("br"))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected an unregistered compression to fail")
+	}
+}
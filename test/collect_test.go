@@ -0,0 +1,44 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestCollectCapped(t *testing.T) {
+	cases := []struct {
+		name     string
+		tokens   []string
+		maxBytes int
+		expected string
+		capped   bool
+	}{
+		{"within the cap", []string{"Hello", ", ", "world"}, 20, "Hello, world", false},
+		{"exactly at the cap", []string{"Hello", ", ", "world"}, 12, "Hello, world", false},
+		{"over the cap", []string{"Hello", ", ", "world"}, 9, "Hello, wo", true},
+		{"multi-byte character at the cap", []string{"caf", "é!"}, 4, "caf", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := newTestClient(t, newStreamGateway(c.tokens...), sdk.ClientOptions{})
+			res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+			if err != nil {
+				t.Fatalf("ChatCompleteStream failed with error %v", err)
+			}
+
+			text, capped, err := res.CollectCapped(c.maxBytes)
+			if err != nil {
+				t.Fatalf("CollectCapped failed with error %v", err)
+			}
+			if text != c.expected || capped != c.capped {
+				t.Fatalf("Expected %q (capped %v), got %q (capped %v)", c.expected, c.capped, text, capped)
+			}
+			if capped && !res.TokenStream.IsClosed() {
+				t.Fatalf("Expected the stream to be closed once the cap was hit")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file to a temporary directory and returns its path.
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "function.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadClientOptions(t *testing.T) {
+	t.Setenv("TEST_FUNCTION_KEY", "secret")
+	path := writeConfig(t, `{
+		"apiKey": "${TEST_FUNCTION_KEY}",
+		"baseUrl": "https://gateway.example.com",
+		"timeout": "10s",
+		"codec": "json"
+	}`)
+
+	options, err := sdk.LoadClientOptions(path)
+	if err != nil {
+		t.Fatalf("LoadClientOptions failed with error %v", err)
+	}
+	if options.ApiKey != "secret" || options.BaseUrl != "https://gateway.example.com" {
+		t.Fatalf("Unexpected options %+v", options)
+	}
+	if options.Timeout != 10*time.Second || options.Codec != sdk.CodecJson {
+		t.Fatalf("Unexpected options %+v", options)
+	}
+}
+
+func TestLoadClientOptionsWithoutApiKey(t *testing.T) {
+	t.Setenv("TEST_FUNCTION_KEY", "")
+	path := writeConfig(t, `{"apiKey": "${TEST_FUNCTION_KEY}"}`)
+
+	_, err := sdk.LoadClientOptions(path)
+	if !errors.Is(err, sdk.MissingApiKeyError) {
+		t.Fatalf("Expected MissingApiKeyError, got %v", err)
+	}
+}
+
+func TestLoadClientOptionsUnknownField(t *testing.T) {
+	path := writeConfig(t, `{"apiKey": "key", "endpoint": "https://gateway.example.com"}`)
+
+	if _, err := sdk.LoadClientOptions(path); err == nil {
+		t.Fatalf("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// rotatingCredentials returns a new key for every call.
+type rotatingCredentials struct {
+	calls atomic.Int32
+}
+
+func (c *rotatingCredentials) Token(ctx context.Context) (string, error) {
+	return "key-" + strconv.Itoa(int(c.calls.Add(1))), nil
+}
+
+// failingCredentials always fails.
+type failingCredentials struct {
+	err error
+}
+
+func (c *failingCredentials) Token(ctx context.Context) (string, error) {
+	return "", c.err
+}
+
+// newStreamKeyEchoGateway creates a gateway which streams back the API key of the request.
+func newStreamKeyEchoGateway() *fakeGateway {
+	gateway := newKeyEchoGateway()
+	gateway.chatCompleteStream = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+		return sendChunks(stream, "assistant", "", req.Header().Get("x-api-key"))
+	}
+	return gateway
+}
+
+func TestCredentialsProvider(t *testing.T) {
+	client, err := sdk.New("", sdk.WithBaseUrl(startGateway(t, newStreamKeyEchoGateway())), sdk.WithCredentialsProvider(&rotatingCredentials{}))
+	if err != nil {
+		t.Fatalf("New failed with error %v", err)
+	}
+	messages := []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}}
+
+	for _, expected := range []string{"key-1", "key-2"} {
+		res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages})
+		if err != nil {
+			t.Fatalf("ChatComplete failed with error %v", err)
+		}
+		if res.Response.Content != expected {
+			t.Fatalf("Expected the request to use %q, got %q", expected, res.Response.Content)
+		}
+	}
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model", Message: messages})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	tokens, err := res.TokenStream.ReadAll()
+	if err != nil {
+		t.Fatalf("Reading the stream failed with error %v", err)
+	}
+	if len(tokens) != 1 || tokens[0] != "key-3" {
+		t.Fatalf("Expected the stream to use %q, got %q", "key-3", tokens)
+	}
+}
+
+func TestStaticApiKeyAuthenticatesStreams(t *testing.T) {
+	client := newTestClient(t, newStreamKeyEchoGateway(), sdk.ClientOptions{ApiKey: "streamkey"})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	tokens, err := res.TokenStream.ReadAll()
+	if err != nil {
+		t.Fatalf("Reading the stream failed with error %v", err)
+	}
+	if len(tokens) != 1 || tokens[0] != "streamkey" {
+		t.Fatalf("Expected the stream to use %q, got %q", "streamkey", tokens)
+	}
+}
+
+func TestCredentialsProviderError(t *testing.T) {
+	providerErr := errors.New("secret manager unavailable")
+	client := newTestClient(t, newStreamKeyEchoGateway(), sdk.ClientOptions{
+		CredentialsProvider: &failingCredentials{err: providerErr},
+	})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if !errors.Is(err, providerErr) || connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("Expected an unauthenticated error wrapping the provider error, got %v", err)
+	}
+
+	_, err = client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if !errors.Is(err, providerErr) || connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("Expected an unauthenticated stream error wrapping the provider error, got %v", err)
+	}
+}
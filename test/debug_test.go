@@ -0,0 +1,69 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"testing"
+)
+
+func TestDebug(t *testing.T) {
+	gateway := newChatGateway()
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	var output bytes.Buffer
+	client, err := sdk.New("secretkey", sdk.WithBaseUrl(startGateway(t, gateway)), sdk.WithDebug(&output))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	messages := []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}}
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model", Message: messages})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, _, err := res.CollectCapped(1024); err != nil {
+		t.Fatalf("CollectCapped failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected Embed to fail")
+	}
+
+	dump := output.String()
+	if strings.Contains(dump, "secretkey") || !strings.Contains(dump, "X-Api-Key: [REDACTED]") {
+		t.Fatalf("Expected the API key to be redacted, got dump:\n%s", dump)
+	}
+	for _, expected := range []string{
+		"--> /apigateway.v1.APIGatewayService/ChatComplete\n",
+		`"Hello"`,
+		"<-- /apigateway.v1.APIGatewayService/ChatComplete ok",
+		`"Hi there"`,
+		"--> /apigateway.v1.APIGatewayService/ChatCompleteStream\n",
+		"<-- /apigateway.v1.APIGatewayService/ChatCompleteStream chunk 3",
+		`" there"`,
+		"<-- /apigateway.v1.APIGatewayService/ChatCompleteStream end after 3 chunks",
+		"<-- /apigateway.v1.APIGatewayService/Embed internal",
+		"gateway crashed",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected dump to contain %q", expected)
+		}
+	}
+	if t.Failed() {
+		t.Logf("Dump:\n%s", dump)
+	}
+}
+
+func TestDebugNil(t *testing.T) {
+	if _, err := sdk.New("mykey", sdk.WithDebug(nil)); err == nil {
+		t.Fatalf("Expected a nil debug writer to be rejected")
+	}
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"testing"
+)
+
+func TestDiffResponses(t *testing.T) {
+	cases := []struct {
+		name       string
+		a          string
+		b          string
+		expected   string
+		similarity float64
+	}{
+		{"identical", "the quick fox", "the  quick\nfox", "the quick fox", 1},
+		{"both empty", "", "", "", 1},
+		{"replacement", "the quick fox", "the slow fox", "the [-quick-] {+slow+} fox", 2.0 / 3},
+		{"insertion", "a c", "a b c", "a {+b+} c", 0.8},
+		{"deletion at the end", "a b c d", "a b", "a b [-c d-]", 2.0 / 3},
+		{"entirely different", "a b", "c", "[-a b-] {+c+}", 0},
+		{"from empty", "", "new text", "{+new text+}", 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := sdk.DiffResponses(c.a, c.b)
+			if result.String() != c.expected {
+				t.Fatalf("Expected diff %q, got %q", c.expected, result.String())
+			}
+			if result.Similarity != c.similarity {
+				t.Fatalf("Expected similarity %v, got %v", c.similarity, result.Similarity)
+			}
+			if result.Equal() != (c.similarity == 1) {
+				t.Fatalf("Expected Equal to be %v", c.similarity == 1)
+			}
+		})
+	}
+}
+
+func TestDiffResponsesReconstructs(t *testing.T) {
+	a := "one two three four five six"
+	b := "zero one three four six seven"
+
+	var reconstructed []string
+	for _, op := range sdk.DiffResponses(a, b).Ops {
+		if op.Kind != sdk.DiffDelete {
+			reconstructed = append(reconstructed, op.Words...)
+		}
+	}
+
+	if got := sdk.DiffResponses(b, strings.Join(reconstructed, " ")); !got.Equal() {
+		t.Fatalf("Expected the ops to reconstruct the second response, got %v", got)
+	}
+}
@@ -0,0 +1,229 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+	"time"
+)
+
+// newEmbedGateway creates a gateway which embeds each input as a vector of ones, with as many dimensions as the input has bytes.
+func newEmbedGateway() *fakeGateway {
+	return &fakeGateway{
+		embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			embedding := make([]float32, len(req.Msg.Input))
+			for i := range embedding {
+				embedding[i] = 1
+			}
+			return connect.NewResponse(&apigatewayv1.EmbedResponse{
+				Model: req.Msg.Model,
+				Data:  []*apigatewayv1.EmbedResponse_Data{{Embedding: embedding}},
+				Usage: &apigatewayv1.EmbedResponse_Usage{PromptTokens: 1, TotalTokens: 1},
+			}), nil
+		},
+	}
+}
+
+func TestEmbedBatch(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{})
+
+	vectors, err := client.EmbedBatch(context.Background(), "model", []string{"abc", "def"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed with error %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 3 || len(vectors[1]) != 3 {
+		t.Fatalf("Unexpected vectors %v", vectors)
+	}
+}
+
+func TestEmbedBatchInconsistentDimensions(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{})
+
+	_, err := client.EmbedBatch(context.Background(), "model", []string{"abc", "def", "ghij"})
+
+	var dimensionErr *sdk.InconsistentDimensionError
+	if !errors.As(err, &dimensionErr) {
+		t.Fatalf("Expected InconsistentDimensionError, got %v", err)
+	}
+	if dimensionErr.Index != 2 || dimensionErr.Expected != 3 || dimensionErr.Actual != 4 {
+		t.Fatalf("Unexpected error contents %+v", dimensionErr)
+	}
+}
+
+func TestBenchmarkEmbed(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{})
+
+	report, err := client.BenchmarkEmbed(context.Background(), "model", []string{"a", "b"}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("BenchmarkEmbed failed with error %v", err)
+	}
+	if report.Requests == 0 || report.Errors != 0 || report.Tokens != report.Requests {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	if report.RequestsPerSecond <= 0 || report.P50Latency <= 0 || report.P99Latency < report.P50Latency {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+}
+
+func TestNormalizeEmbedInput(t *testing.T) {
+	cases := map[string]string{
+		"  hello \t\n world  ": "hello world",
+		"cafe\u0301":           "caf\u00e9",
+		"Mixed Case":           "Mixed Case",
+		"":                     "",
+	}
+
+	for input, expected := range cases {
+		if normalized := sdk.NormalizeEmbedInput(input); normalized != expected {
+			t.Errorf("NormalizeEmbedInput(%q): expected %q, got %q", input, expected, normalized)
+		}
+	}
+}
+
+func TestEmbedInputNormalizer(t *testing.T) {
+	var received string
+	gateway := &fakeGateway{
+		embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			received = req.Msg.Input
+			return connect.NewResponse(&apigatewayv1.EmbedResponse{}), nil
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{EmbedInputNormalizer: sdk.NormalizeEmbedInput})
+
+	request := &apigatewayv1.EmbedRequest{Model: "model", Input: " a  b "}
+	if _, err := client.Embed(context.Background(), request); err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if received != "a b" {
+		t.Fatalf("Expected normalized input %q, got %q", "a b", received)
+	}
+	if request.Input != " a  b " {
+		t.Fatalf("Expected the caller's request not to be modified")
+	}
+}
+
+func TestEmbedEmptyInput(t *testing.T) {
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	calls := 0
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		calls++
+		return embed(ctx, req)
+	}
+
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model"}); !errors.Is(err, sdk.NoInputsError) {
+		t.Fatalf("Expected NoInputsError from Embed, got %v", err)
+	}
+	if _, err := client.EmbedBatch(context.Background(), "model", nil); !errors.Is(err, sdk.NoInputsError) {
+		t.Fatalf("Expected NoInputsError from EmbedBatch, got %v", err)
+	}
+
+	client = newTestClient(t, gateway, sdk.ClientOptions{AllowEmptyEmbedInput: true})
+	res, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if len(res.Data) != 0 || res.Model != "model" {
+		t.Fatalf("Expected an empty response, got %v", res)
+	}
+	vectors, err := client.EmbedBatch(context.Background(), "model", nil)
+	if err != nil || len(vectors) != 0 {
+		t.Fatalf("Expected no vectors, got %v and error %v", vectors, err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("Expected no requests to be made, got %d", calls)
+	}
+}
+
+func TestEmbedBatchEmptyInputs(t *testing.T) {
+	inputs := []string{"", "abc", "", "def"}
+
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{})
+	if _, err := client.EmbedBatch(context.Background(), "model", inputs); !errors.Is(err, sdk.NoInputsError) {
+		t.Fatalf("Expected NoInputsError, got %v", err)
+	}
+
+	client = newTestClient(t, newEmbedGateway(), sdk.ClientOptions{AllowEmptyEmbedInput: true})
+	vectors, err := client.EmbedBatch(context.Background(), "model", inputs)
+	if err != nil {
+		t.Fatalf("EmbedBatch failed with error %v", err)
+	}
+	if len(vectors) != 4 || len(vectors[0]) != 0 || len(vectors[1]) != 3 || len(vectors[2]) != 0 || len(vectors[3]) != 3 {
+		t.Fatalf("Unexpected vectors %v", vectors)
+	}
+
+	partial, err := client.EmbedBatchPartial(context.Background(), "model", inputs)
+	if err != nil {
+		t.Fatalf("EmbedBatchPartial failed with error %v", err)
+	}
+	if len(partial) != 4 || len(partial[0]) != 0 || len(partial[1]) != 3 || len(partial[2]) != 0 || len(partial[3]) != 3 {
+		t.Fatalf("Unexpected vectors %v", partial)
+	}
+}
+
+func TestEmbedBatchPartialCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(c context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		if req.Msg.Input == "ccc" {
+			// Simulate the job being interrupted while this input is in flight.
+			cancel()
+			<-c.Done()
+			return nil, connect.NewError(connect.CodeCanceled, c.Err())
+		}
+		return embed(c, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	vectors, err := client.EmbedBatchPartial(ctx, "model", []string{"aaa", "bbb", "ccc", "ddd"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 3 || len(vectors[1]) != 3 {
+		t.Fatalf("Expected the first two vectors only, got %v", vectors)
+	}
+	if _, ok := vectors[2]; ok {
+		t.Fatalf("Expected the interrupted input to be absent")
+	}
+}
+
+func TestEmbedBatchPartialComplete(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{})
+
+	vectors, err := client.EmbedBatchPartial(context.Background(), "model", []string{"ab", "cd"})
+	if err != nil {
+		t.Fatalf("EmbedBatchPartial failed with error %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 2 || len(vectors[1]) != 2 {
+		t.Fatalf("Unexpected vectors %v", vectors)
+	}
+}
+
+func TestEstimateEmbeddingStorage(t *testing.T) {
+	cases := []struct {
+		vectors    int
+		dimensions int
+		dtype      sdk.DType
+		expected   int64
+	}{
+		{1_000_000, 1536, sdk.DTypeFloat32, 6_144_000_000},
+		{1_000_000, 1536, sdk.DTypeFloat64, 12_288_000_000},
+		{1_000_000, 1536, sdk.DTypeInt8, 1_536_000_000},
+		{0, 768, sdk.DTypeFloat32, 0},
+		{-1, 768, sdk.DTypeFloat32, 0},
+	}
+
+	for _, c := range cases {
+		if actual := sdk.EstimateEmbeddingStorage(c.vectors, c.dimensions, c.dtype); actual != c.expected {
+			t.Fatalf("Expected %d bytes for %d vectors of %d dimensions, got %d", c.expected, c.vectors, c.dimensions, actual)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"crypto/tls"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientOptionsFromEnv(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvBaseUrl, "https://gateway.example.com")
+	t.Setenv(sdk.EnvTimeout, "1m30s")
+	t.Setenv(sdk.EnvCodec, "JSON")
+
+	options, err := sdk.ClientOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("ClientOptionsFromEnv failed with error %v", err)
+	}
+	if options.ApiKey != "envkey" || options.BaseUrl != "https://gateway.example.com" {
+		t.Fatalf("Unexpected options %+v", options)
+	}
+	if options.Timeout != 90*time.Second || options.Codec != sdk.CodecJson {
+		t.Fatalf("Unexpected options %+v", options)
+	}
+}
+
+func TestClientOptionsFromEnvDefaults(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvBaseUrl, "")
+	t.Setenv(sdk.EnvTimeout, "")
+	t.Setenv(sdk.EnvCodec, "")
+
+	options, err := sdk.ClientOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("ClientOptionsFromEnv failed with error %v", err)
+	}
+	if options.BaseUrl != "" || options.Timeout != 0 || options.Codec != sdk.CodecProto {
+		t.Fatalf("Expected default options, got %+v", options)
+	}
+}
+
+func TestClientOptionsFromEnvTimeoutSeconds(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvTimeout, "2.5")
+
+	options, err := sdk.ClientOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("ClientOptionsFromEnv failed with error %v", err)
+	}
+	if options.Timeout != 2500*time.Millisecond {
+		t.Fatalf("Expected a 2.5s timeout, got %v", options.Timeout)
+	}
+}
+
+func TestClientOptionsFromEnvWithoutApiKey(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "")
+
+	_, err := sdk.ClientOptionsFromEnv()
+	if !errors.Is(err, sdk.MissingApiKeyError) {
+		t.Fatalf("Expected MissingApiKeyError, got %v", err)
+	}
+}
+
+func TestClientOptionsFromEnvInvalidTimeout(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvTimeout, "soon")
+
+	if _, err := sdk.ClientOptionsFromEnv(); err == nil {
+		t.Fatalf("Expected an error for an invalid timeout")
+	}
+}
+
+func TestClientOptionsFromEnvProxy(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+
+	// The proxy answers every request itself, so a reply proves the request went through it.
+	var requests atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	t.Cleanup(proxy.Close)
+	t.Setenv(sdk.EnvProxyUrl, proxy.URL)
+
+	options, err := sdk.ClientOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("ClientOptionsFromEnv failed with error %v", err)
+	}
+	if options.ProxyUrl != proxy.URL || options.HttpClient != nil {
+		t.Fatalf("Expected the proxy URL to be set as ProxyUrl, without an HTTP client, got %q and %v", options.ProxyUrl, options.HttpClient)
+	}
+
+	// Transport options can still be added to the loaded options.
+	options.BaseUrl = "http://gateway.invalid"
+	options.TlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	options.Timeouts.Dial = time.Second
+	client, err := sdk.NewClient(options)
+	if err != nil {
+		t.Fatalf("NewClient failed with error %v", err)
+	}
+
+	// The proxy's reply is not a valid response, but it proves that the request went through it.
+	client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if requests.Load() == 0 {
+		t.Fatalf("Expected the request to go through the proxy")
+	}
+}
+
+func TestClientOptionsFromEnvInvalidProxy(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvProxyUrl, "proxy.internal")
+
+	if _, err := sdk.ClientOptionsFromEnv(); err == nil || !strings.Contains(err.Error(), sdk.EnvProxyUrl) {
+		t.Fatalf("Expected an error naming %s, got %v", sdk.EnvProxyUrl, err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(sdk.EnvApiKey, "envkey")
+	t.Setenv(sdk.EnvBaseUrl, startGateway(t, newKeyEchoGateway()))
+	t.Setenv(sdk.EnvTimeout, "")
+	t.Setenv(sdk.EnvCodec, "")
+	t.Setenv(sdk.EnvProxyUrl, "")
+
+	client, err := sdk.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "envkey" {
+		t.Fatalf("Expected the request to use the API key from the environment, got %q", res.Response.Content)
+	}
+
+	t.Setenv(sdk.EnvApiKey, "")
+	_, err = sdk.NewClientFromEnv()
+	if !errors.Is(err, sdk.MissingApiKeyError) || !strings.Contains(err.Error(), sdk.EnvApiKey) {
+		t.Fatalf("Expected MissingApiKeyError naming %s, got %v", sdk.EnvApiKey, err)
+	}
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestMethodErrorAttachesMethodName(t *testing.T) {
+	gatewayErr := connect.NewError(connect.CodeInvalidArgument, errors.New("bad input"))
+	gateway := &fakeGateway{
+		embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			return nil, gatewayErr
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+
+	var methodErr *sdk.MethodError
+	if !errors.As(err, &methodErr) {
+		t.Fatalf("Expected MethodError, got %v", err)
+	}
+	if methodErr.Method != "Embed" {
+		t.Fatalf("Expected method Embed, got %q", methodErr.Method)
+	}
+	if methodErr.Code != connect.CodeInvalidArgument {
+		t.Fatalf("Expected code invalid_argument, got %v", methodErr.Code)
+	}
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Message() != "bad input" {
+		t.Fatalf("Expected the underlying Connect error to be preserved, got %v", err)
+	}
+}
+
+func TestMethodErrorPreservesSentinels(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	_, err := client.TextToImage(context.Background(), nil)
+
+	var methodErr *sdk.MethodError
+	if !errors.As(err, &methodErr) || methodErr.Method != "TextToImage" {
+		t.Fatalf("Expected MethodError for TextToImage, got %v", err)
+	}
+	if !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected errors.Is to match NilRequestError, got %v", err)
+	}
+}
+
+// appError is an application error type that ErrorWrapper converts SDK errors to.
+type appError struct {
+	operation string
+	err       error
+}
+
+func (e *appError) Error() string {
+	return e.operation + ": " + e.err.Error()
+}
+
+func (e *appError) Unwrap() error {
+	return e.err
+}
+
+func TestErrorWrapper(t *testing.T) {
+	gateway := newStreamGateway("Hello")
+	gateway.chatCompleteStream = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+		if err := sendChunks(stream, "assistant", "", "Hello"); err != nil {
+			return err
+		}
+		return connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	var methods []string
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		ErrorWrapper: func(method string, err error) error {
+			methods = append(methods, method)
+			return &appError{operation: "inference", err: err}
+		},
+	})
+
+	_, err := client.ChatComplete(context.Background(), nil)
+	var wrapped *appError
+	if !errors.As(err, &wrapped) || !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected a wrapped NilRequestError, got %v", err)
+	}
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	_, err = res.TokenStream.ReadAll()
+	if !errors.As(err, &wrapped) || connect.CodeOf(err) != connect.CodeInternal {
+		t.Fatalf("Expected a wrapped stream error, got %v", err)
+	}
+
+	if len(methods) != 2 || methods[0] != "ChatComplete" || methods[1] != "ChatCompleteStream" {
+		t.Fatalf("Expected the wrapper to be called once per error, got %v", methods)
+	}
+}
+
+func TestApiErrors(t *testing.T) {
+	cases := []struct {
+		code      connect.Code
+		message   string
+		target    any
+		retryable bool
+	}{
+		{connect.CodeUnauthenticated, "invalid api key", new(*sdk.AuthenticationError), false},
+		{connect.CodePermissionDenied, "model not allowed", new(*sdk.AuthenticationError), false},
+		{connect.CodeResourceExhausted, "too many requests", new(*sdk.RateLimitError), true},
+		{connect.CodeResourceExhausted, "monthly quota exceeded", new(*sdk.QuotaExceededError), false},
+		{connect.CodeNotFound, "no such model", new(*sdk.ModelNotFoundError), false},
+		{connect.CodeInvalidArgument, "input is required", new(*sdk.InvalidRequestError), false},
+		{connect.CodeInternal, "gateway crashed", new(*sdk.ServerError), true},
+		{connect.CodeUnavailable, "model overloaded", new(*sdk.ServerError), true},
+	}
+	for _, c := range cases {
+		t.Run(c.message, func(t *testing.T) {
+			gateway := &fakeGateway{
+				embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+					err := connect.NewError(c.code, errors.New(c.message))
+					err.Meta().Set(sdk.RequestIdHeader, "req-123")
+					return nil, err
+				},
+			}
+			client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+			_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+
+			if !errors.As(err, c.target) {
+				t.Fatalf("Expected %T, got %#v", c.target, err)
+			}
+			var apiErr sdk.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an ApiError, got %v", err)
+			}
+			if apiErr.Code() != c.code || apiErr.RequestId() != "req-123" || apiErr.Retryable() != c.retryable {
+				t.Fatalf("Expected code %v, request ID req-123 and retryable %v, got %v, %q and %v",
+					c.code, c.retryable, apiErr.Code(), apiErr.RequestId(), apiErr.Retryable())
+			}
+			var connectErr *connect.Error
+			if !errors.As(err, &connectErr) || connectErr.Message() != c.message {
+				t.Fatalf("Expected the underlying Connect error to be preserved, got %v", err)
+			}
+		})
+	}
+}
+
+func TestApiErrorsModel(t *testing.T) {
+	gateway := newStreamGateway("Hello")
+	gateway.chatCompleteStream = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+		return connect.NewError(connect.CodeNotFound, errors.New("no such model"))
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	_, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "missing"})
+
+	var notFoundErr *sdk.ModelNotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Model != "missing" {
+		t.Fatalf("Expected ModelNotFoundError for model missing, got %v", err)
+	}
+}
+
+func TestApiErrorsClientSide(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{MaxPromptChars: 3})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+
+	var invalidErr *sdk.InvalidRequestError
+	if !errors.As(err, &invalidErr) || invalidErr.RequestId() != "" {
+		t.Fatalf("Expected InvalidRequestError without a request ID, got %v", err)
+	}
+	var promptErr *sdk.PromptTooLongError
+	if !errors.As(err, &promptErr) {
+		t.Fatalf("Expected the PromptTooLongError to be preserved, got %v", err)
+	}
+}
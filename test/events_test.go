@@ -0,0 +1,62 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestReadEventRoleChange(t *testing.T) {
+	gateway := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Let me check."); err != nil {
+				return err
+			}
+			if err := sendChunks(stream, "tool", `{"temp": 20}`); err != nil {
+				return err
+			}
+			if err := sendChunks(stream, "", " More tool output"); err != nil {
+				return err
+			}
+			return sendChunks(stream, "assistant", "It is 20 degrees.")
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+
+	var events []sdk.StreamEvent
+	for {
+		event, err := res.ReadEvent()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadEvent failed with error %v", err)
+		}
+		events = append(events, event)
+	}
+
+	expected := []sdk.StreamEvent{
+		{Kind: sdk.StreamEventToken, Role: "assistant", Token: "Let me check."},
+		{Kind: sdk.StreamEventRoleChange, Role: "tool"},
+		{Kind: sdk.StreamEventToken, Role: "tool", Token: `{"temp": 20}`},
+		{Kind: sdk.StreamEventToken, Role: "tool", Token: " More tool output"},
+		{Kind: sdk.StreamEventRoleChange, Role: "assistant"},
+		{Kind: sdk.StreamEventToken, Role: "assistant", Token: "It is 20 degrees."},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("Expected events %+v, got %+v", expected, events)
+	}
+	if res.Role != "assistant" {
+		t.Fatalf("Expected Role to remain the initial role, got %q", res.Role)
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newNamedGateway creates a gateway which answers chat requests with its name, after the given delay.
+func newNamedGateway(name string, delay time.Duration) *fakeGateway {
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: name},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", name)
+		},
+	}
+}
+
+// Returns the URL of a server which is no longer listening.
+func closedServerUrl() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestFailover(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailable.Close)
+	secondary := startGateway(t, newNamedGateway("secondary", 0))
+
+	for name, primary := range map[string]string{"unreachable": closedServerUrl(), "unavailable": unavailable.URL} {
+		client, err := sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", BaseUrls: []string{primary, secondary}})
+		if err != nil {
+			t.Fatalf("Client creation failed with error %v", err)
+		}
+
+		metadata := &sdk.CallMetadata{}
+		ctx := sdk.WithCallMetadata(context.Background(), metadata)
+		res, err := client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{Model: "model"})
+		if err != nil || res.Response.Content != "secondary" {
+			t.Fatalf("Expected the %s primary to fail over to the secondary, got %q (error %v)", name, res.GetResponse().GetContent(), err)
+		}
+		if metadata.BaseUrl != secondary {
+			t.Fatalf("Expected the metadata to record the secondary %q, got %q", secondary, metadata.BaseUrl)
+		}
+
+		stream, err := client.ChatCompleteStream(ctx, &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+		if err != nil {
+			t.Fatalf("ChatCompleteStream failed with error %v", err)
+		}
+		if read, err := stream.TokenStream.ReadAll(); err != nil || strings.Join(read, "") != "secondary" {
+			t.Fatalf("Expected the %s primary to fail over to the secondary for streams, got %q (error %v)", name, read, err)
+		}
+	}
+}
+
+func TestFailoverPrimary(t *testing.T) {
+	primary := startGateway(t, newNamedGateway("primary", 0))
+	secondary := startGateway(t, newNamedGateway("secondary", 0))
+	client, err := sdk.New("mykey", sdk.WithBaseUrls(primary, secondary))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	metadata := &sdk.CallMetadata{}
+	res, err := client.ChatComplete(sdk.WithCallMetadata(context.Background(), metadata), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil || res.Response.Content != "primary" || metadata.BaseUrl != primary {
+		t.Fatalf("Expected the primary to serve the request, got %q from %q (error %v)", res.GetResponse().GetContent(), metadata.BaseUrl, err)
+	}
+
+	if _, err := sdk.New("mykey", sdk.WithBaseUrls(primary, "not a url")); err == nil {
+		t.Fatalf("Expected an invalid base URL to be rejected")
+	}
+}
+
+func TestFailoverHedging(t *testing.T) {
+	primary := startGateway(t, newNamedGateway("primary", 2*time.Second))
+	secondary := startGateway(t, newNamedGateway("secondary", 0))
+	client, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKey:     "mykey",
+		BaseUrls:   []string{primary, secondary},
+		HedgeDelay: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	started := time.Now()
+	metadata := &sdk.CallMetadata{}
+	res, err := client.ChatComplete(sdk.WithCallMetadata(context.Background(), metadata), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil || res.Response.Content != "secondary" || metadata.BaseUrl != secondary {
+		t.Fatalf("Expected the hedged request to be served by the secondary, got %q from %q (error %v)", res.GetResponse().GetContent(), metadata.BaseUrl, err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Expected the hedged request not to wait for the slow primary, took %v", elapsed)
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+// newFallbackGateway creates a gateway on which "missing" does not exist, "down" is unavailable, "locked" rejects the API key,
+// and every other model works.
+func newFallbackGateway() *fakeGateway {
+	modelError := func(model string) error {
+		switch model {
+		case "missing":
+			return connect.NewError(connect.CodeNotFound, errors.New("model not found"))
+		case "down":
+			return connect.NewError(connect.CodeUnavailable, errors.New("model unavailable"))
+		case "locked":
+			return connect.NewError(connect.CodeUnauthenticated, errors.New("bad key"))
+		}
+		return nil
+	}
+
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			if err := modelError(req.Msg.Model); err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: req.Msg.Model},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := modelError(req.Msg.Model); err != nil {
+				return err
+			}
+			return sendChunks(stream, "assistant", "", req.Msg.Model)
+		},
+	}
+}
+
+func TestFallbackModels(t *testing.T) {
+	client := newTestClient(t, newFallbackGateway(), sdk.ClientOptions{
+		FallbackModels: []string{"down", "backup"},
+	})
+
+	request := &apigatewayv1.ChatCompleteRequest{Model: "missing"}
+	var metadata sdk.CallMetadata
+	res, err := client.ChatComplete(sdk.WithCallMetadata(context.Background(), &metadata), request)
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "backup" || metadata.Model != "backup" {
+		t.Fatalf("Expected the backup model to serve the request, got %q (metadata %q)", res.Response.Content, metadata.Model)
+	}
+	if request.Model != "missing" {
+		t.Fatalf("Expected the caller's request not to be modified")
+	}
+}
+
+func TestFallbackModelsStream(t *testing.T) {
+	client := newTestClient(t, newFallbackGateway(), sdk.ClientOptions{
+		FallbackModels: []string{"backup"},
+	})
+
+	var metadata sdk.CallMetadata
+	res, err := client.ChatCompleteStream(sdk.WithCallMetadata(context.Background(), &metadata), &apigatewayv1.ChatCompleteStreamRequest{Model: "down"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	defer res.TokenStream.Close()
+
+	token, err := res.TokenStream.Read()
+	if err != nil || token != "backup" || metadata.Model != "backup" {
+		t.Fatalf("Expected the backup model to serve the stream, got %q (metadata %q, error %v)", token, metadata.Model, err)
+	}
+}
+
+func TestFallbackModelsIgnoresOtherErrors(t *testing.T) {
+	client := newTestClient(t, newFallbackGateway(), sdk.ClientOptions{
+		FallbackModels: []string{"backup"},
+	})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "locked"})
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("Expected the authentication error to be returned without fallback, got %v", err)
+	}
+}
+
+func TestRewriteOnRetry(t *testing.T) {
+	var lastErrs []connect.Code
+	client := newTestClient(t, newFallbackGateway(), sdk.ClientOptions{
+		FallbackModels: []string{"down", "backup"},
+		RewriteOnRetry: func(req any, attempt int, lastErr error) any {
+			lastErrs = append(lastErrs, connect.CodeOf(lastErr))
+			request := req.(*apigatewayv1.ChatCompleteRequest)
+			if attempt == 2 {
+				request.Model = "rewritten"
+			}
+			return request
+		},
+	})
+
+	var metadata sdk.CallMetadata
+	res, err := client.ChatComplete(sdk.WithCallMetadata(context.Background(), &metadata), &apigatewayv1.ChatCompleteRequest{Model: "missing"})
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "rewritten" || metadata.Model != "rewritten" {
+		t.Fatalf("Expected the rewritten request to be sent, got %q (metadata %q)", res.Response.Content, metadata.Model)
+	}
+	if len(lastErrs) != 2 || lastErrs[0] != connect.CodeNotFound || lastErrs[1] != connect.CodeUnavailable {
+		t.Fatalf("Expected the hook to receive the previous errors, got %v", lastErrs)
+	}
+}
+
+func TestRewriteOnRetryWrongType(t *testing.T) {
+	client := newTestClient(t, newFallbackGateway(), sdk.ClientOptions{
+		FallbackModels: []string{"backup"},
+		RewriteOnRetry: func(req any, attempt int, lastErr error) any {
+			return &apigatewayv1.EmbedRequest{Model: "backup"}
+		},
+	})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "missing"})
+
+	var typeErr *sdk.RetryRequestTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected RetryRequestTypeError, got %v", err)
+	}
+}
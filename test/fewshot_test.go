@@ -0,0 +1,76 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+// newTranscriptGateway creates a gateway which replies with the roles and contents of the request messages, as "role:content" lines.
+func newTranscriptGateway() *fakeGateway {
+	transcript := func(messages []*apigatewayv1.ChatCompleteMessage) string {
+		var text string
+		for _, message := range messages {
+			text += message.Role + ":" + message.Content + "\n"
+		}
+		return text
+	}
+
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: transcript(req.Msg.Message)},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", transcript(req.Msg.Message))
+		},
+	}
+}
+
+func TestFewShotExamples(t *testing.T) {
+	client := newTestClient(t, newTranscriptGateway(), sdk.ClientOptions{
+		FewShotExamples: []sdk.ChatExample{{User: "2+2", Assistant: "4"}},
+	})
+	messages := []*apigatewayv1.ChatCompleteMessage{
+		{Role: "system", Content: "Answer with a number."},
+		{Role: "user", Content: "3+3"},
+	}
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"default", context.Background(), "system:Answer with a number.\nuser:2+2\nassistant:4\nuser:3+3\n"},
+		{"override", sdk.WithFewShotExamples(context.Background(), sdk.ChatExample{User: "1+1", Assistant: "2"}), "system:Answer with a number.\nuser:1+1\nassistant:2\nuser:3+3\n"},
+		{"disabled", sdk.WithFewShotExamples(context.Background()), "system:Answer with a number.\nuser:3+3\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := client.ChatComplete(c.ctx, &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages})
+			if err != nil {
+				t.Fatalf("ChatComplete failed with error %v", err)
+			}
+			if res.Response.Content != c.expected {
+				t.Fatalf("Expected messages %q, got %q", c.expected, res.Response.Content)
+			}
+
+			stream, err := client.ChatCompleteStream(c.ctx, &apigatewayv1.ChatCompleteStreamRequest{Model: "model", Message: messages})
+			if err != nil {
+				t.Fatalf("ChatCompleteStream failed with error %v", err)
+			}
+			text, _, err := stream.CollectCapped(1024)
+			if err != nil || text != c.expected {
+				t.Fatalf("Expected streamed messages %q, got %q and error %v", c.expected, text, err)
+			}
+		})
+	}
+
+	if len(messages) != 2 || messages[1].Content != "3+3" {
+		t.Fatalf("Expected the caller's messages not to be modified")
+	}
+}
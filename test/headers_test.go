@@ -0,0 +1,66 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newUserAgentEchoGateway creates a gateway which answers chat requests with their User-Agent and X-Partner headers.
+func newUserAgentEchoGateway() *fakeGateway {
+	echo := func(header http.Header) string {
+		return header.Get("User-Agent") + "," + header.Get("X-Partner")
+	}
+
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: echo(req.Header())},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", echo(req.Header()))
+		},
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	baseUrl := startGateway(t, newUserAgentEchoGateway())
+	client, err := sdk.New("mykey",
+		sdk.WithBaseUrl(baseUrl),
+		sdk.WithUserAgent("my-app/1.2"),
+		sdk.WithDefaultHeaders(map[string]string{"X-Partner": "acme"}),
+	)
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil || res.Response.Content != "my-app/1.2,acme" {
+		t.Fatalf("Expected the user agent and default headers, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+
+	stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if read, err := stream.TokenStream.ReadAll(); err != nil || strings.Join(read, "") != "my-app/1.2,acme" {
+		t.Fatalf("Expected the user agent and default headers on streams, got %q (error %v)", read, err)
+	}
+
+	// Call headers replace default headers of the same name.
+	res, err = client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}, sdk.WithCallHeader("x-partner", "other"))
+	if err != nil || res.Response.Content != "my-app/1.2,other" {
+		t.Fatalf("Expected the call header to replace the default header, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+}
+
+func TestDefaultHeadersApiKey(t *testing.T) {
+	if _, err := sdk.New("mykey", sdk.WithDefaultHeaders(map[string]string{"X-API-Key": "other"})); err == nil {
+		t.Fatalf("Expected the API key header to be rejected as a default header")
+	}
+}
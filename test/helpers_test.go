@@ -0,0 +1,103 @@
+package test
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeGateway is a programmable API gateway used by tests.
+// Any handler left nil responds with CodeUnimplemented.
+type fakeGateway struct {
+	apigatewayv1connect.UnimplementedAPIGatewayServiceHandler
+
+	chatComplete       func(context.Context, *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error)
+	chatCompleteStream func(context.Context, *connect.Request[apigatewayv1.ChatCompleteStreamRequest], *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error
+	embed              func(context.Context, *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error)
+	textToImage        func(context.Context, *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error)
+	transcribe         func(context.Context, *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error)
+}
+
+func (g *fakeGateway) ChatComplete(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+	if g.chatComplete == nil {
+		return g.UnimplementedAPIGatewayServiceHandler.ChatComplete(ctx, req)
+	}
+	return g.chatComplete(ctx, req)
+}
+
+func (g *fakeGateway) ChatCompleteStream(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+	if g.chatCompleteStream == nil {
+		return g.UnimplementedAPIGatewayServiceHandler.ChatCompleteStream(ctx, req, stream)
+	}
+	return g.chatCompleteStream(ctx, req, stream)
+}
+
+func (g *fakeGateway) Embed(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+	if g.embed == nil {
+		return g.UnimplementedAPIGatewayServiceHandler.Embed(ctx, req)
+	}
+	return g.embed(ctx, req)
+}
+
+func (g *fakeGateway) TextToImage(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+	if g.textToImage == nil {
+		return g.UnimplementedAPIGatewayServiceHandler.TextToImage(ctx, req)
+	}
+	return g.textToImage(ctx, req)
+}
+
+func (g *fakeGateway) Transcribe(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+	if g.transcribe == nil {
+		return g.UnimplementedAPIGatewayServiceHandler.Transcribe(ctx, req)
+	}
+	return g.transcribe(ctx, req)
+}
+
+// startGateway serves the gateway over HTTP for the duration of the test and returns its base URL.
+func startGateway(t *testing.T, gateway *fakeGateway) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(gateway))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// newTestClient creates a client pointed at the given gateway.
+// The options are used as-is, except that the API key and base URL are filled in when unset.
+func newTestClient(t *testing.T, gateway *fakeGateway, options sdk.ClientOptions) *sdk.Client {
+	t.Helper()
+
+	if options.ApiKey == "" {
+		options.ApiKey = "mykey"
+	}
+	if options.BaseUrl == "" {
+		options.BaseUrl = startGateway(t, gateway)
+	}
+
+	client, err := sdk.NewClient(options)
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	return client
+}
+
+// sendChunks sends each content string as a separate stream chunk with the given role.
+func sendChunks(stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse], role string, contents ...string) error {
+	for _, content := range contents {
+		err := stream.Send(&apigatewayv1.ChatCompleteStreamResponse{
+			Response: &apigatewayv1.ChatCompleteMessage{Role: role, Content: content},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"testing"
+)
+
+// recordHooks returns client options which record every lifecycle hook call into events,
+// as "hook method model tokens" for requests and responses, and "hook method model code" for errors.
+func recordHooks(events *[]string) sdk.ClientOptions {
+	record := func(hook string) func(ctx context.Context, info sdk.CallInfo) {
+		return func(ctx context.Context, info sdk.CallInfo) {
+			if info.Err != nil {
+				*events = append(*events, fmt.Sprintf("%s %s %s %v", hook, info.Method, info.Model, info.Code))
+			} else {
+				*events = append(*events, fmt.Sprintf("%s %s %s %d", hook, info.Method, info.Model, info.Usage.TotalTokens))
+			}
+		}
+	}
+
+	return sdk.ClientOptions{
+		OnRequest:  record("request"),
+		OnResponse: record("response"),
+		OnError:    record("error"),
+	}
+}
+
+func TestHooksUnary(t *testing.T) {
+	gateway := &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			if req.Msg.Model == "missing" {
+				return nil, connect.NewError(connect.CodeNotFound, errors.New("unknown model"))
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response:   &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: "Hi"},
+				TokenCount: 3,
+			}), nil
+		},
+	}
+	var events []string
+	client := newTestClient(t, gateway, recordHooks(&events))
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "missing"}); connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("Expected ChatComplete to fail with CodeNotFound, got %v", err)
+	}
+
+	expected := []string{
+		"request ChatComplete model 0",
+		"response ChatComplete model 3",
+		"request ChatComplete missing 0",
+		"error ChatComplete missing not_found",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expected events:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
+
+func TestHooksStream(t *testing.T) {
+	var events []string
+	client := newTestClient(t, newStreamGateway("Hello", " world"), recordHooks(&events))
+	request := &apigatewayv1.ChatCompleteStreamRequest{Model: "model"}
+
+	// A stream read to its end reports its usage.
+	res, err := client.ChatCompleteStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if events[len(events)-1] != "request ChatCompleteStream model 0" {
+		t.Fatalf("Expected OnRequest to be called when the stream is opened, got %v", events)
+	}
+	if err := drainStream(t, res); err != nil {
+		t.Fatalf("Reading the stream failed with error %v", err)
+	}
+	res.TokenStream.Close()
+
+	// A stream closed early ends successfully, without usage.
+	res, err = client.ChatCompleteStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, err := res.TokenStream.Read(); err != nil {
+		t.Fatalf("Read failed with error %v", err)
+	}
+	res.TokenStream.Close()
+
+	expected := []string{
+		"request ChatCompleteStream model 0",
+		"response ChatCompleteStream model 2",
+		"request ChatCompleteStream model 0",
+		"response ChatCompleteStream model 0",
+	}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expected events:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+
+	// A stream which fails to open reports its error.
+	events = nil
+	client = newTestClient(t, &fakeGateway{}, recordHooks(&events))
+	if _, err := client.ChatCompleteStream(context.Background(), request); err == nil {
+		t.Fatalf("Expected ChatCompleteStream to fail, as the gateway does not implement it")
+	}
+	if strings.Join(events, "\n") != "request ChatCompleteStream model 0\nerror ChatCompleteStream model unimplemented" {
+		t.Fatalf("Expected the stream error to be reported, got %v", events)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// newIdempotencyGateway creates a gateway which fails every other image request with CodeUnavailable,
+// and records the idempotency key of every request it receives.
+func newIdempotencyGateway(keys *[]string) *fakeGateway {
+	return &fakeGateway{
+		textToImage: func(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+			*keys = append(*keys, req.Header().Get(sdk.IdempotencyKeyHeader))
+			if len(*keys)%2 == 1 {
+				return nil, connect.NewError(connect.CodeUnavailable, errors.New("try again"))
+			}
+			return connect.NewResponse(&apigatewayv1.TextToImageResponse{}), nil
+		},
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var keys []string
+	client := newTestClient(t, newIdempotencyGateway(&keys), sdk.ClientOptions{
+		Retry: &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	request := &apigatewayv1.TextToImageRequest{Model: "model", Prompt: "a cat"}
+
+	if _, err := client.TextToImage(context.Background(), request, sdk.WithIdempotencyKey("key-1")); err != nil {
+		t.Fatalf("TextToImage failed with error %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "key-1" || keys[1] != "key-1" {
+		t.Fatalf("Expected both attempts to use the same key, got %q", keys)
+	}
+
+	keys = nil
+	if _, err := client.TextToImage(context.Background(), request); err != nil {
+		t.Fatalf("TextToImage failed with error %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "" || keys[1] != "" {
+		t.Fatalf("Expected no key without the option, got %q", keys)
+	}
+}
+
+func TestAutoIdempotencyKeys(t *testing.T) {
+	var keys []string
+	client := newTestClient(t, newIdempotencyGateway(&keys), sdk.ClientOptions{
+		Retry:               &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		AutoIdempotencyKeys: true,
+	})
+	request := &apigatewayv1.TextToImageRequest{Model: "model", Prompt: "a cat"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.TextToImage(context.Background(), request); err != nil {
+			t.Fatalf("TextToImage failed with error %v", err)
+		}
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(keys) != 4 || !uuid.MatchString(keys[0]) {
+		t.Fatalf("Expected generated keys for 4 attempts, got %q", keys)
+	}
+	if keys[0] != keys[1] || keys[2] != keys[3] || keys[0] == keys[2] {
+		t.Fatalf("Expected each call to reuse its own key across retries, got %q", keys)
+	}
+
+	keys = nil
+	if _, err := client.TextToImage(context.Background(), request, sdk.WithIdempotencyKey("explicit")); err != nil {
+		t.Fatalf("TextToImage failed with error %v", err)
+	}
+	if keys[0] != "explicit" || keys[1] != "explicit" {
+		t.Fatalf("Expected an explicit key to take precedence, got %q", keys)
+	}
+}
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// startImageServer serves a fixed image body at /image.png, and a 404 everywhere else.
+func startImageServer(t *testing.T, body []byte, contentType string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/image.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestFetchImage(t *testing.T) {
+	baseUrl := startImageServer(t, []byte("image"), "image/png")
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	res, err := client.FetchImage(context.Background(), baseUrl+"/image.png")
+	if err != nil {
+		t.Fatalf("FetchImage failed with error %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("Unexpected content type %q", res.Header.Get("Content-Type"))
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil || string(body) != "image" {
+		t.Fatalf("Unexpected body %q (error %v)", body, err)
+	}
+}
+
+func TestFetchImageNotFound(t *testing.T) {
+	baseUrl := startImageServer(t, nil, "image/png")
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	_, err := client.FetchImage(context.Background(), baseUrl+"/missing.png")
+
+	var statusErr *sdk.UnexpectedStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected UnexpectedStatusError with status 404, got %v", err)
+	}
+}
+
+// encodeTestImage encodes a small two-color image in the given format.
+func encodeTestImage(t *testing.T, format sdk.ImageFormat) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(img, image.Rect(0, 0, 2, 4), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(2, 0, 4, 4), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+
+	var buffer bytes.Buffer
+	var err error
+	switch format {
+	case sdk.ImageFormatJpeg:
+		err = jpeg.Encode(&buffer, img, nil)
+	case sdk.ImageFormatGif:
+		err = gif.Encode(&buffer, img, nil)
+	default:
+		err = png.Encode(&buffer, img)
+	}
+	if err != nil {
+		t.Fatalf("Encoding the test image failed with error %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestSaveImage(t *testing.T) {
+	cases := []struct {
+		name   string
+		source sdk.ImageFormat
+		target sdk.ImageFormat
+	}{
+		{"png to jpeg", sdk.ImageFormatPng, sdk.ImageFormatJpeg},
+		{"jpeg to png", sdk.ImageFormatJpeg, sdk.ImageFormatPng},
+		{"png to gif", sdk.ImageFormatPng, sdk.ImageFormatGif},
+		{"gif to png", sdk.ImageFormatGif, sdk.ImageFormatPng},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			baseUrl := startImageServer(t, encodeTestImage(t, c.source), "image/"+string(c.source))
+			client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+			var buffer bytes.Buffer
+			err := client.SaveImage(context.Background(), baseUrl+"/image.png", &buffer, &sdk.SaveImageOptions{Format: c.target})
+			if err != nil {
+				t.Fatalf("SaveImage failed with error %v", err)
+			}
+
+			img, format, err := image.Decode(&buffer)
+			if err != nil {
+				t.Fatalf("Decoding the saved image failed with error %v", err)
+			}
+			if sdk.ImageFormat(format) != c.target {
+				t.Fatalf("Expected format %q, got %q", c.target, format)
+			}
+			if img.Bounds() != image.Rect(0, 0, 4, 4) {
+				t.Fatalf("Unexpected image bounds %v", img.Bounds())
+			}
+		})
+	}
+}
+
+func TestSaveImageSameFormat(t *testing.T) {
+	source := encodeTestImage(t, sdk.ImageFormatJpeg)
+	baseUrl := startImageServer(t, source, "image/jpeg")
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	for _, options := range []*sdk.SaveImageOptions{nil, {Format: sdk.ImageFormatJpeg, JpegQuality: 10}} {
+		var buffer bytes.Buffer
+		if err := client.SaveImage(context.Background(), baseUrl+"/image.png", &buffer, options); err != nil {
+			t.Fatalf("SaveImage failed with error %v", err)
+		}
+		if !bytes.Equal(buffer.Bytes(), source) {
+			t.Fatalf("Expected the image to be saved as-is with options %+v", options)
+		}
+	}
+}
+
+func TestSaveImageUnsupportedFormat(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	err := client.SaveImage(context.Background(), "http://127.0.0.1:0/image.png", io.Discard, &sdk.SaveImageOptions{Format: "webp"})
+
+	var formatErr *sdk.UnsupportedImageFormatError
+	if !errors.As(err, &formatErr) || formatErr.Format != "webp" {
+		t.Fatalf("Expected UnsupportedImageFormatError for webp, got %v", err)
+	}
+}
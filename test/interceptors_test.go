@@ -0,0 +1,91 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"path"
+	"strings"
+	"testing"
+)
+
+// auditInterceptor records the method and API key of every request, including streams.
+type auditInterceptor struct {
+	calls []string
+}
+
+func (i *auditInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		i.calls = append(i.calls, path.Base(req.Spec().Procedure)+":"+req.Header().Get("x-api-key"))
+		return next(ctx, req)
+	}
+}
+
+func (i *auditInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		i.calls = append(i.calls, path.Base(spec.Procedure))
+		return next(ctx, spec)
+	}
+}
+
+func (i *auditInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+func TestInterceptors(t *testing.T) {
+	audit := &auditInterceptor{}
+	client := newTestClient(t, newStreamGateway("Hello"), sdk.ClientOptions{
+		Interceptors: []connect.Interceptor{audit},
+	})
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err == nil {
+		t.Fatalf("Expected ChatComplete to fail, as the gateway does not implement it")
+	}
+	stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	stream.TokenStream.Close()
+
+	// Unary requests are already authenticated when interceptors see them.
+	if calls := strings.Join(audit.calls, ","); calls != "ChatComplete:mykey,ChatCompleteStream" {
+		t.Fatalf("Expected the interceptor to see both calls, got %q", calls)
+	}
+}
+
+func TestInterceptorsMock(t *testing.T) {
+	var order []string
+	logging := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			order = append(order, "logging")
+			return next(ctx, req)
+		}
+	})
+	mock := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			order = append(order, "mock")
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: "mocked"},
+			}), nil
+		}
+	})
+
+	// The mock answers without a request being made, so the gateway is never reached.
+	client, err := sdk.New("mykey", sdk.WithBaseUrl(closedServerUrl()), sdk.WithInterceptors(logging, mock))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil || res.Response.Content != "mocked" {
+		t.Fatalf("Expected the mocked response, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+	if strings.Join(order, ",") != "logging,mock" {
+		t.Fatalf("Expected the interceptors to run in order, got %v", order)
+	}
+
+	if _, err := sdk.New("mykey", sdk.WithInterceptors(nil)); err == nil {
+		t.Fatalf("Expected a nil interceptor to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readJsonFields reads every field of a JSON field stream, formatted as name=value, and the error that ended it.
+func readJsonFields(stream *sdk.JsonFieldStream) ([]string, error) {
+	var fields []string
+	for {
+		field, err := stream.Read()
+		if err != nil {
+			return fields, err
+		}
+		fields = append(fields, field.Name+"="+string(field.Value))
+	}
+}
+
+func TestStreamJsonFields(t *testing.T) {
+	tokens := []string{"```json\n{", `"name": "Ad`, `a \"A\" L", `, `"age":4`, `2, "tags": ["x", "}"`, `], "address": {"city": "`, `Paris"}, "ok": true}`, "\n```"}
+	stream := sdk.StreamJsonFields(sdk.StaticChatCompleteStream("assistant", tokens, nil))
+
+	fields, err := readJsonFields(stream)
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("Read failed with error %v", err)
+	}
+
+	expected := []string{
+		`name="Ada \"A\" L"`,
+		`age=42`,
+		`tags=["x", "}"]`,
+		`address={"city": "Paris"}`,
+		`ok=true`,
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Expected fields %q, got %q", expected, fields)
+	}
+}
+
+func TestStreamJsonFieldsProgressive(t *testing.T) {
+	tokens := []string{`{"title": "Hi"`, `, "body": "...`, `"}`}
+	stream := sdk.StreamJsonFields(sdk.StaticChatCompleteStream("assistant", tokens, nil))
+
+	field, err := stream.Read()
+	if err != nil || field.Name != "title" {
+		t.Fatalf("Expected the title field, got %v and error %v", field, err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed with error %v", err)
+	}
+	if _, err := stream.Read(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Expected EOF after Close, got %v", err)
+	}
+}
+
+func TestStreamJsonFieldsEmptyObject(t *testing.T) {
+	fields, err := readJsonFields(sdk.StreamJsonFields(sdk.StaticChatCompleteStream("assistant", []string{"{ }"}, nil)))
+	if !errors.Is(err, io.EOF) || len(fields) != 0 {
+		t.Fatalf("Expected no fields, got %q and error %v", fields, err)
+	}
+}
+
+func TestStreamJsonFieldsMalformed(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"truncated", `{"a": 1, "b": "unfinished`, []string{"a=1"}},
+		{"invalid value", `{"a": 1, "b": tru}`, []string{"a=1"}},
+		{"trailing comma", `{"a": 1,}`, []string{"a=1"}},
+		{"missing colon", `{"a" 1}`, nil},
+		{"no object", `Sorry, I cannot do that.`, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens := strings.SplitAfter(c.text, " ")
+			fields, err := readJsonFields(sdk.StreamJsonFields(sdk.StaticChatCompleteStream("assistant", tokens, nil)))
+
+			var malformedErr *sdk.MalformedJsonError
+			if !errors.As(err, &malformedErr) {
+				t.Fatalf("Expected MalformedJsonError, got %v", err)
+			}
+			if !reflect.DeepEqual(fields, c.expected) {
+				t.Fatalf("Expected fields %q before the error, got %q", c.expected, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newKeyEchoGateway creates a gateway which replies with the API key of each chat request.
+func newKeyEchoGateway() *fakeGateway {
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: req.Header().Get("x-api-key")},
+			}), nil
+		},
+	}
+}
+
+// writeKeyFile writes an API key file to a temporary directory and returns its path.
+func writeKeyFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	return path
+}
+
+func TestApiKeyFile(t *testing.T) {
+	client, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKeyFile: writeKeyFile(t, "file-key\r\n"),
+		BaseUrl:    startGateway(t, newKeyEchoGateway()),
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "file-key" {
+		t.Fatalf("Expected the key from the file to be sent, got %q", res.Response.Content)
+	}
+}
+
+func TestApiKeyFilePrecedence(t *testing.T) {
+	client := newTestClient(t, newKeyEchoGateway(), sdk.ClientOptions{
+		ApiKey:     "explicit-key",
+		ApiKeyFile: filepath.Join(t.TempDir(), "missing"),
+	})
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "explicit-key" {
+		t.Fatalf("Expected ApiKey to take precedence over ApiKeyFile, got %q", res.Response.Content)
+	}
+}
+
+func TestApiKeyFileErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		expected error
+	}{
+		{"missing", filepath.Join(t.TempDir(), "missing"), fs.ErrNotExist},
+		{"empty", writeKeyFile(t, "\n"), sdk.EmptyApiKeyFileError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := sdk.NewClient(sdk.ClientOptions{ApiKeyFile: c.path})
+
+			var fileErr *sdk.ApiKeyFileError
+			if !errors.As(err, &fileErr) || fileErr.Path != c.path {
+				t.Fatalf("Expected ApiKeyFileError for %q, got %v", c.path, err)
+			}
+			if !errors.Is(err, c.expected) {
+				t.Fatalf("Expected the error to wrap %v, got %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestApiKeyFileReload(t *testing.T) {
+	path := writeKeyFile(t, "old-key\n")
+	client, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKeyFile:               path,
+		ApiKeyFileReloadInterval: 20 * time.Millisecond,
+		BaseUrl:                  startGateway(t, newKeyEchoGateway()),
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed with error %v", err)
+	}
+
+	sentKey := func() string {
+		res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+		if err != nil {
+			t.Fatalf("ChatComplete failed with error %v", err)
+		}
+		return res.Response.Content
+	}
+
+	// Rotate the key by renaming a new file over the old one.
+	replacement := writeKeyFile(t, "new-key\n")
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatalf("Failed to replace key file: %v", err)
+	}
+	if key := sentKey(); key != "old-key" {
+		t.Fatalf("Expected the old key before the reload interval passed, got %q", key)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if key := sentKey(); key != "new-key" {
+		t.Fatalf("Expected the new key after the reload interval passed, got %q", key)
+	}
+
+	// A missing file keeps the current key.
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove key file: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if key := sentKey(); key != "new-key" {
+		t.Fatalf("Expected the key to be kept while the file is missing, got %q", key)
+	}
+}
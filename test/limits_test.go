@@ -0,0 +1,101 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"testing"
+)
+
+func newLimitedClient(t *testing.T) *sdk.Client {
+	return newTestClient(t, &fakeGateway{}, sdk.ClientOptions{
+		ModelLimits: map[string]sdk.ModelLimits{
+			"small": {MaxInputTokens: 10, MaxMessages: 2},
+			"tiny":  {MaxRequestBytes: 20},
+		},
+	})
+}
+
+func TestCheckRequestSize(t *testing.T) {
+	client := newLimitedClient(t)
+
+	err := client.CheckRequestSize(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "small",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected a small request to pass, got %v", err)
+	}
+
+	err = client.CheckRequestSize(context.Background(), &apigatewayv1.EmbedRequest{Model: "unknown", Input: strings.Repeat("a", 1000)})
+	if err != nil {
+		t.Fatalf("Expected a model without limits to pass, got %v", err)
+	}
+}
+
+func TestCheckRequestSizeTooManyTokens(t *testing.T) {
+	client := newLimitedClient(t)
+
+	err := client.CheckRequestSize(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "small",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: strings.Repeat("a", 100)}},
+	})
+
+	var sizeErr *sdk.PayloadTooLargeError
+	if !errors.As(err, &sizeErr) || sizeErr.Tokens != 25 || sizeErr.MaxTokens != 10 {
+		t.Fatalf("Expected PayloadTooLargeError for 25 tokens, got %v", err)
+	}
+}
+
+func TestCheckRequestSizeTooManyBytes(t *testing.T) {
+	client := newLimitedClient(t)
+
+	err := client.CheckRequestSize(context.Background(), &apigatewayv1.EmbedRequest{Model: "tiny", Input: strings.Repeat("a", 100)})
+
+	var sizeErr *sdk.PayloadTooLargeError
+	if !errors.As(err, &sizeErr) || sizeErr.Bytes <= 100 || sizeErr.MaxBytes != 20 {
+		t.Fatalf("Expected PayloadTooLargeError for bytes, got %v", err)
+	}
+}
+
+func TestCheckRequestSizeTooManyMessages(t *testing.T) {
+	client := newLimitedClient(t)
+
+	message := &apigatewayv1.ChatCompleteMessage{Role: "user", Content: "Hi"}
+	err := client.CheckRequestSize(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{
+		Model:   "small",
+		Message: []*apigatewayv1.ChatCompleteMessage{message, message, message},
+	})
+
+	var messagesErr *sdk.TooManyMessagesError
+	if !errors.As(err, &messagesErr) || messagesErr.Messages != 3 {
+		t.Fatalf("Expected TooManyMessagesError, got %v", err)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{
+		ModelLimits: map[string]sdk.ModelLimits{
+			"large": {MaxInputTokens: 128000, MaxOutputTokens: 4096},
+			"tiny":  {MaxRequestBytes: 20},
+		},
+	})
+
+	maxInput, maxOutput, err := client.ContextWindow(context.Background(), "large")
+	if err != nil || maxInput != 128000 || maxOutput != 4096 {
+		t.Fatalf("Expected a context window of 128000 and 4096 tokens, got %d and %d (error %v)", maxInput, maxOutput, err)
+	}
+
+	maxInput, maxOutput, err = client.ContextWindow(context.Background(), "tiny")
+	if err != nil || maxInput != 0 || maxOutput != 0 {
+		t.Fatalf("Expected unknown token limits for a model with only a size limit, got %d and %d (error %v)", maxInput, maxOutput, err)
+	}
+
+	_, _, err = client.ContextWindow(context.Background(), "unknown")
+	var notFoundErr *sdk.ModelNotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Model != "unknown" {
+		t.Fatalf("Expected ModelNotFoundError for an unknown model, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"connectrpc.com/connect"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// parseLogs parses the records written by a JSON slog handler.
+func parseLogs(t *testing.T, output *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Parsing log line %q failed with error %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	gateway := newChatGateway()
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, err := sdk.New("secretkey", sdk.WithBaseUrl(startGateway(t, gateway)), sdk.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	messages := []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "private prompt"}}
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model", Message: messages})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, _, err := res.CollectCapped(1024); err != nil {
+		t.Fatalf("CollectCapped failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected Embed to fail")
+	}
+
+	if strings.Contains(output.String(), "secretkey") || strings.Contains(output.String(), "private prompt") {
+		t.Fatalf("Expected the API key and prompt to be redacted, got logs:\n%s", output.String())
+	}
+
+	var summary []string
+	for _, record := range parseLogs(t, &output) {
+		line := record["level"].(string) + " " + record["msg"].(string) + " " + record["method"].(string)
+		if tokens, ok := record["completion_tokens"]; ok {
+			line += " tokens=" + fmt.Sprint(tokens)
+		}
+		if code, ok := record["code"]; ok {
+			line += " code=" + code.(string)
+		}
+		summary = append(summary, line)
+	}
+	expected := []string{
+		"DEBUG function request ChatComplete",
+		"INFO function response ChatComplete tokens=2",
+		"DEBUG function request ChatCompleteStream",
+		"INFO function response ChatCompleteStream tokens=2",
+		"DEBUG function request Embed",
+		"ERROR function request failed Embed code=internal",
+	}
+	if strings.Join(summary, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Expected logs:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(summary, "\n"))
+	}
+}
+
+func TestLoggerContent(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newTestClient(t, newChatGateway(), sdk.ClientOptions{Logger: logger, LogContent: true})
+
+	request := &apigatewayv1.ChatCompleteRequest{Model: "model", Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "visible prompt"}}}
+	if _, err := client.ChatComplete(context.Background(), request); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+
+	if !strings.Contains(output.String(), "visible prompt") || !strings.Contains(output.String(), "Hi there") {
+		t.Fatalf("Expected the prompt and response to be logged, got logs:\n%s", output.String())
+	}
+	if !strings.Contains(output.String(), "[REDACTED]") || strings.Contains(output.String(), "mykey") {
+		t.Fatalf("Expected the API key to be redacted, got logs:\n%s", output.String())
+	}
+}
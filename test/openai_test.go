@@ -0,0 +1,99 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+// formatMessages renders messages as "role:content" lines, for comparison.
+func formatMessages(messages []*apigatewayv1.ChatCompleteMessage) string {
+	var text string
+	for _, message := range messages {
+		text += message.Role + ":" + message.Content + "\n"
+	}
+	return text
+}
+
+func TestMessagesFromOpenAiJson(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"array", `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}]`, "system:Be brief.\nuser:Hi\n"},
+		{"dataset", `{"messages": [{"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello"}]}`, "user:Hi\nassistant:Hello\n"},
+		{"text parts", `[{"role": "user", "content": [{"type": "text", "text": "Hello, "}, {"type": "text", "text": "world"}]}]`, "user:Hello, world\n"},
+		{"null content and tool calls", `[{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1"}]}]`, "assistant:\n"},
+		{"unknown fields", `[{"role": "user", "name": "alice", "content": "Hi", "extra": 1}]`, "user:Hi\n"},
+		{"developer role", `[{"role": "developer", "content": "Be brief."}]`, "system:Be brief.\n"},
+		{"empty", `[]`, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			messages, err := sdk.MessagesFromOpenAiJson([]byte(c.data))
+			if err != nil {
+				t.Fatalf("MessagesFromOpenAiJson failed with error %v", err)
+			}
+			if formatMessages(messages) != c.expected {
+				t.Fatalf("Expected messages %q, got %q", c.expected, formatMessages(messages))
+			}
+		})
+	}
+}
+
+func TestMessagesFromOpenAiJsonErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		data  string
+		index int
+	}{
+		{"missing role", `[{"role": "user", "content": "Hi"}, {"content": "Hello"}]`, 1},
+		{"image part", `[{"role": "user", "content": [{"type": "image_url", "image_url": {"url": "https://example.com/a.png"}}]}]`, 0},
+		{"invalid content", `[{"role": "user", "content": 42}]`, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := sdk.MessagesFromOpenAiJson([]byte(c.data))
+			var messageErr *sdk.OpenAiMessageError
+			if !errors.As(err, &messageErr) {
+				t.Fatalf("Expected an OpenAiMessageError, got %v", err)
+			}
+			if messageErr.Index != c.index {
+				t.Fatalf("Expected the error for message %d, got %d", c.index, messageErr.Index)
+			}
+		})
+	}
+
+	if _, err := sdk.MessagesFromOpenAiJson([]byte(`not json`)); err == nil {
+		t.Fatalf("Expected an error for invalid JSON")
+	}
+}
+
+func TestMessagesOpenAiJsonRoundTrip(t *testing.T) {
+	messages := []*apigatewayv1.ChatCompleteMessage{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Quote \"this\"\nplease"},
+		{Role: "assistant", Content: ""},
+	}
+
+	data, err := sdk.MessagesToOpenAiJson(messages)
+	if err != nil {
+		t.Fatalf("MessagesToOpenAiJson failed with error %v", err)
+	}
+	expectedJson := `[{"role":"system","content":"Be brief."},{"role":"user","content":"Quote \"this\"\nplease"},{"role":"assistant","content":""}]`
+	if string(data) != expectedJson {
+		t.Fatalf("Expected JSON %s, got %s", expectedJson, data)
+	}
+
+	decoded, err := sdk.MessagesFromOpenAiJson(data)
+	if err != nil {
+		t.Fatalf("MessagesFromOpenAiJson failed with error %v", err)
+	}
+	if formatMessages(decoded) != formatMessages(messages) {
+		t.Fatalf("Expected messages %q after a round trip, got %q", formatMessages(messages), formatMessages(decoded))
+	}
+}
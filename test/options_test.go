@@ -0,0 +1,95 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	baseUrl := startGateway(t, newKeyEchoGateway())
+
+	client, err := sdk.New("optionkey",
+		sdk.WithBaseUrl(baseUrl),
+		sdk.WithHttpClient(http.DefaultClient),
+		sdk.WithTimeout(time.Second),
+		sdk.WithCodec(sdk.CodecJson),
+		sdk.WithFallbackModels("other-model"),
+		sdk.WithOptions(func(options *sdk.ClientOptions) {
+			options.MaxPromptChars = 100
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if res.Response.Content != "optionkey" {
+		t.Fatalf("Expected the request to use the API key passed to New, got %q", res.Response.Content)
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	cases := []struct {
+		name   string
+		apiKey string
+		option sdk.ClientOption
+	}{
+		{"relative base URL", "key", sdk.WithBaseUrl("/gateway")},
+		{"base URL without scheme", "key", sdk.WithBaseUrl("gateway.example.com")},
+		{"nil HTTP client", "key", sdk.WithHttpClient(nil)},
+		{"zero timeout", "key", sdk.WithTimeout(0)},
+		{"unknown codec", "key", sdk.WithCodec(sdk.Codec(42))},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := sdk.New(c.apiKey, c.option); err == nil {
+				t.Fatalf("Expected New to fail")
+			}
+		})
+	}
+
+	if _, err := sdk.New(""); !errors.Is(err, sdk.MissingApiKeyError) {
+		t.Fatalf("Expected MissingApiKeyError, got %v", err)
+	}
+}
+
+func TestNewWithRateLimit(t *testing.T) {
+	client, err := sdk.New("mykey", sdk.WithBaseUrl(startGateway(t, newEmbedGateway())), sdk.WithRateLimit(0.001, 2))
+	if err != nil {
+		t.Fatalf("New failed with error %v", err)
+	}
+	embed := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := embed(); err != nil {
+			t.Fatalf("Request %d within the burst failed with error %v", i+1, err)
+		}
+	}
+	if err := embed(); err == nil {
+		t.Fatalf("Expected the request after the burst to be throttled")
+	}
+
+	for _, option := range []sdk.ClientOption{sdk.WithRateLimit(0, 1), sdk.WithRateLimit(10, 0)} {
+		if _, err := sdk.New("mykey", option); err == nil {
+			t.Fatalf("Expected New to fail for an invalid rate limit")
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/otelfn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"strings"
+	"testing"
+)
+
+// collectMetrics returns the metrics recorded by reader, keyed by instrument name.
+func collectMetrics(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect failed with error %v", err)
+	}
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+// sumOf returns the total of a counter across all attribute sets.
+func sumOf(data metricdata.Aggregation) int64 {
+	sum, _ := data.(metricdata.Sum[int64])
+	var total int64
+	for _, point := range sum.DataPoints {
+		total += point.Value
+	}
+	return total
+}
+
+func TestOtelMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	metrics, err := otelfn.NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	if err != nil {
+		t.Fatalf("NewMetrics failed with error %v", err)
+	}
+
+	gateway := newChatGateway()
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	options := sdk.ClientOptions{}
+	metrics.Instrument(&options)
+	if len(options.Interceptors) != 1 || len(options.ConnectOptions) != 0 {
+		t.Fatalf("Expected Instrument to add an interceptor to Interceptors, got %v and %v", options.Interceptors, options.ConnectOptions)
+	}
+	client := newTestClient(t, gateway, options)
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, _, err := res.CollectCapped(1024); err != nil {
+		t.Fatalf("CollectCapped failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected Embed to fail")
+	}
+
+	collected := collectMetrics(t, reader)
+	if requests := sumOf(collected[otelfn.RequestsMetric]); requests != 3 {
+		t.Fatalf("Expected 3 requests, got %d", requests)
+	}
+	if errorCount := sumOf(collected[otelfn.ErrorsMetric]); errorCount != 1 {
+		t.Fatalf("Expected 1 error, got %d", errorCount)
+	}
+
+	duration, _ := collected[otelfn.DurationMetric].(metricdata.Histogram[float64])
+	var durations uint64
+	for _, point := range duration.DataPoints {
+		durations += point.Count
+	}
+	if durations != 3 {
+		t.Fatalf("Expected 3 durations, got %d", durations)
+	}
+
+	// ChatComplete reports 2 tokens, and the stream yields 2 tokens.
+	usage, _ := collected[otelfn.TokenUsageMetric].(metricdata.Histogram[int64])
+	var tokens int64
+	for _, point := range usage.DataPoints {
+		tokens += point.Sum
+	}
+	if tokens != 4 {
+		t.Fatalf("Expected 4 tokens, got %d", tokens)
+	}
+}
+
+func TestOtelTracing(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagator) })
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracing := otelfn.NewTracing(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	var traceparent string
+	gateway := newChatGateway()
+	chatComplete := gateway.chatComplete
+	gateway.chatComplete = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+		traceparent = req.Header().Get("traceparent")
+		return chatComplete(ctx, req)
+	}
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	options := sdk.ClientOptions{}
+	tracing.Instrument(&options)
+	client := newTestClient(t, gateway, options)
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, _, err := res.CollectCapped(1024); err != nil {
+		t.Fatalf("CollectCapped failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected Embed to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	attributes := func(span tracetest.SpanStub) map[string]string {
+		values := map[string]string{}
+		for _, attribute := range span.Attributes {
+			values[string(attribute.Key)] = attribute.Value.Emit()
+		}
+		return values
+	}
+
+	chat, stream, embed := attributes(spans[0]), attributes(spans[1]), attributes(spans[2])
+	if spans[0].Name != "apigateway.v1.APIGatewayService/ChatComplete" || chat["gen_ai.request.model"] != "model" || chat["gen_ai.usage.output_tokens"] != "2" {
+		t.Fatalf("Unexpected ChatComplete span %q with attributes %v", spans[0].Name, chat)
+	}
+	if stream["rpc.method"] != "ChatCompleteStream" || stream["gen_ai.usage.output_tokens"] != "2" || len(spans[1].Events) != 1 {
+		t.Fatalf("Unexpected ChatCompleteStream span with attributes %v and events %v", stream, spans[1].Events)
+	}
+	if embed["rpc.connect_rpc.error_code"] != "internal" || spans[2].Status.Code.String() != "Error" {
+		t.Fatalf("Expected the Embed span to record the error, got attributes %v and status %v", embed, spans[2].Status)
+	}
+
+	// The trace context is propagated to the gateway.
+	if !strings.Contains(traceparent, spans[0].SpanContext.TraceID().String()) {
+		t.Fatalf("Expected the trace context of the ChatComplete span to be propagated, got %q", traceparent)
+	}
+}
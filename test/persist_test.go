@@ -0,0 +1,84 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestChatCompleteStreamPersist(t *testing.T) {
+	client := newTestClient(t, newStreamGateway("Hello", ", ", "world"), sdk.ClientOptions{})
+
+	var log bytes.Buffer
+	content, err := client.ChatCompleteStreamPersist(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"}, &log)
+	if err != nil {
+		t.Fatalf("ChatCompleteStreamPersist failed with error %v", err)
+	}
+	if content != "Hello, world" || log.String() != content {
+		t.Fatalf("Expected content and log to be %q, got %q and %q", "Hello, world", content, log.String())
+	}
+}
+
+func TestChatCompleteStreamPersistTruncated(t *testing.T) {
+	gateway := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Hello", ", "); err != nil {
+				return err
+			}
+			return connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	var log bytes.Buffer
+	content, err := client.ChatCompleteStreamPersist(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"}, &log)
+	if connect.CodeOf(err) != connect.CodeInternal {
+		t.Fatalf("Expected the stream error, got %v", err)
+	}
+	if content != "Hello, " || log.String() != content {
+		t.Fatalf("Expected partial content and log to be %q, got %q and %q", "Hello, ", content, log.String())
+	}
+}
+
+// failingWriter accepts a number of writes, and then fails.
+type failingWriter struct {
+	written bytes.Buffer
+	writes  int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.writes--
+	return w.written.Write(p)
+}
+
+func TestPersistTrailingError(t *testing.T) {
+	streamErr := connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	res := sdk.StaticChatCompleteStream("assistant", []string{"Hello", ", ", "world"}, streamErr)
+
+	var log bytes.Buffer
+	content, err := res.Persist(&log)
+	if !errors.Is(err, streamErr) {
+		t.Fatalf("Expected the stream error, got %v", err)
+	}
+	if content != "Hello, world" || log.String() != content {
+		t.Fatalf("Expected partial content and log to be %q, got %q and %q", "Hello, world", content, log.String())
+	}
+
+	// A failed write stops the stream, and the token which could not be written is still returned.
+	res = sdk.StaticChatCompleteStream("assistant", []string{"Hello", ", ", "world"}, nil)
+	writer := &failingWriter{writes: 1}
+	content, err = res.Persist(writer)
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+	if content != "Hello, " || writer.written.String() != "Hello" || !res.TokenStream.IsClosed() {
+		t.Fatalf("Expected content %q, log %q and a closed stream, got %q and %q", "Hello, ", "Hello", content, writer.written.String())
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/promfn"
+	"github.com/prometheus/client_golang/prometheus"
+	"strings"
+	"testing"
+)
+
+// gatherMetrics returns the samples gathered from registry, keyed by metric name and sorted labels, such as `name{a="1",b="2"}`.
+// Counters are gathered as their value, and histograms as their sample count.
+func gatherMetrics(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed with error %v", err)
+	}
+
+	samples := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.Metric {
+			var labels []string
+			for _, label := range m.Label {
+				labels = append(labels, label.GetName()+`="`+label.GetValue()+`"`)
+			}
+			key := family.GetName() + "{" + strings.Join(labels, ",") + "}"
+			if m.Counter != nil {
+				samples[key] = m.Counter.GetValue()
+			} else if m.Histogram != nil {
+				samples[key] = float64(m.Histogram.GetSampleCount())
+			}
+		}
+	}
+	return samples
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	gateway := newChatGateway()
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+	}
+	registry := prometheus.NewRegistry()
+	client, err := sdk.New("mykey", sdk.WithBaseUrl(startGateway(t, gateway)), promfn.WithMetrics(registry))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, _, err := res.CollectCapped(1024); err != nil {
+		t.Fatalf("CollectCapped failed with error %v", err)
+	}
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err == nil {
+		t.Fatalf("Expected Embed to fail")
+	}
+
+	// A second client shares the collectors of the first.
+	if _, err := promfn.NewMetrics(registry); err != nil {
+		t.Fatalf("Expected the collectors to be shared, got error %v", err)
+	}
+
+	samples := gatherMetrics(t, registry)
+	expected := map[string]float64{
+		`function_client_requests_total{code="ok",method="ChatComplete",model="model"}`:         1,
+		`function_client_requests_total{code="ok",method="ChatCompleteStream",model="model"}`:   1,
+		`function_client_requests_total{code="internal",method="Embed",model="model"}`:          1,
+		`function_client_request_duration_seconds{method="ChatComplete",model="model"}`:         1,
+		`function_client_request_duration_seconds{method="Embed",model="model"}`:                1,
+		`function_client_stream_duration_seconds{method="ChatCompleteStream",model="model"}`:    1,
+		`function_client_tokens_total{method="ChatComplete",model="model",type="output"}`:       2,
+		`function_client_tokens_total{method="ChatCompleteStream",model="model",type="output"}`: 2,
+	}
+	for key, value := range expected {
+		if samples[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, samples[key])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestMaxPromptChars(t *testing.T) {
+	gateway := newChatGateway()
+	calls := 0
+	chatComplete := gateway.chatComplete
+	gateway.chatComplete = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+		calls++
+		return chatComplete(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{MaxPromptChars: 10})
+
+	messages := []*apigatewayv1.ChatCompleteMessage{
+		{Role: "system", Content: "héllo"},
+		{Role: "user", Content: "world"},
+	}
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages}); err != nil {
+		t.Fatalf("Expected a prompt at the limit to be sent, got error %v", err)
+	}
+
+	messages = append(messages, &apigatewayv1.ChatCompleteMessage{Role: "user", Content: "!"})
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model", Message: messages})
+
+	var tooLongErr *sdk.PromptTooLongError
+	if !errors.As(err, &tooLongErr) {
+		t.Fatalf("Expected PromptTooLongError, got %v", err)
+	}
+	if tooLongErr.Chars != 11 || tooLongErr.MaxChars != 10 {
+		t.Fatalf("Unexpected error contents %+v", tooLongErr)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected the long prompt not to be sent, got %d calls", calls)
+	}
+
+	_, err = client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model", Message: messages})
+	if !errors.As(err, &tooLongErr) {
+		t.Fatalf("Expected PromptTooLongError from ChatCompleteStream, got %v", err)
+	}
+}
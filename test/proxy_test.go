@@ -0,0 +1,129 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"testing"
+)
+
+// newProxyClient creates a client talking to a proxy which forwards everything to upstream.
+func newProxyClient(t *testing.T, upstream *fakeGateway) *sdk.Client {
+	upstreamClient := newTestClient(t, upstream, sdk.ClientOptions{})
+
+	proxy := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return upstreamClient.ForwardChatCompleteStream(ctx, req.Msg, stream)
+		},
+	}
+	return newTestClient(t, proxy, sdk.ClientOptions{})
+}
+
+func TestForwardChatCompleteStream(t *testing.T) {
+	client := newProxyClient(t, &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", "", "Hello", ", ", "world")
+		},
+	})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if res.Role != "assistant" {
+		t.Fatalf("Expected role assistant, got %q", res.Role)
+	}
+
+	var text string
+	for {
+		token, err := res.TokenStream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed with error %v", err)
+		}
+		text += token
+	}
+	if text != "Hello, world" {
+		t.Fatalf("Expected %q, got %q", "Hello, world", text)
+	}
+}
+
+func TestForwardChatCompleteStreamError(t *testing.T) {
+	client := newProxyClient(t, &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return connect.NewError(connect.CodeResourceExhausted, errors.New("slow down"))
+		},
+	})
+
+	res, err := client.ChatCompleteStreamRaw(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStreamRaw failed with error %v", err)
+	}
+	defer res.Close()
+
+	if res.Receive() {
+		t.Fatalf("Expected no chunks")
+	}
+	if err := res.Err(); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("Expected the upstream error code to be forwarded, got %v", err)
+	}
+}
+
+func TestForwardChatCompleteStreamWrappedError(t *testing.T) {
+	upstream := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Hello"); err != nil {
+				return err
+			}
+			return connect.NewError(connect.CodeResourceExhausted, errors.New("slow down"))
+		},
+	}
+	upstreamClient := newTestClient(t, upstream, sdk.ClientOptions{
+		ErrorWrapper: func(method string, err error) error {
+			return fmt.Errorf("upstream: %w", err)
+		},
+	})
+
+	forwardErrs := make(chan error, 1)
+	proxy := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			err := upstreamClient.ForwardChatCompleteStream(ctx, req.Msg, stream)
+			forwardErrs <- err
+			return err
+		},
+	}
+	client := newTestClient(t, proxy, sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStreamRaw(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStreamRaw failed with error %v", err)
+	}
+	defer res.Close()
+
+	for res.Receive() {
+	}
+	err = res.Err()
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("Expected the upstream error code to be forwarded, got %v", err)
+	}
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Message() != "slow down" {
+		t.Fatalf("Expected the upstream error message to be forwarded, got %v", err)
+	}
+
+	forwardErr := <-forwardErrs
+	var methodErr *sdk.MethodError
+	if !errors.As(forwardErr, &methodErr) || methodErr.Method != "ForwardChatCompleteStream" {
+		t.Fatalf("Expected the handler to receive a *MethodError, got %v", forwardErr)
+	}
+	var rateLimitErr *sdk.RateLimitError
+	if !errors.As(forwardErr, &rateLimitErr) {
+		t.Fatalf("Expected the handler to receive a *RateLimitError, got %v", forwardErr)
+	}
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/tokens"
+	"strings"
+	"testing"
+)
+
+// countWords counts whitespace-separated words, with a separator counting as one token.
+func countWords(text string) int {
+	if text == sdk.ContextBlockSeparator {
+		return 1
+	}
+	return len(strings.Fields(text))
+}
+
+func TestBuildContextBlock(t *testing.T) {
+	chunks := []string{"one two", "three four", "five"}
+
+	cases := map[int]string{
+		0: "",
+		1: "",
+		2: "one two",
+		4: "one two",
+		5: "one two" + sdk.ContextBlockSeparator + "three four",
+		6: "one two" + sdk.ContextBlockSeparator + "three four",
+		7: "one two" + sdk.ContextBlockSeparator + "three four" + sdk.ContextBlockSeparator + "five",
+	}
+
+	for maxTokens, expected := range cases {
+		if block := sdk.BuildContextBlock(chunks, maxTokens, countWords); block != expected {
+			t.Errorf("With a budget of %d, expected %q, got %q", maxTokens, expected, block)
+		}
+	}
+}
+
+func TestBuildContextBlockStopsAtFirstChunkOverBudget(t *testing.T) {
+	chunks := []string{"one", "two three four five", "six"}
+
+	if block := sdk.BuildContextBlock(chunks, 4, countWords); block != "one" {
+		t.Fatalf("Expected later chunks not to be added after one exceeded the budget, got %q", block)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for text, expected := range map[string]int{"": 0, "abcd": 1, "héllo": 2, "What is the capital of Italy?": 8} {
+		if actual := sdk.EstimateTokens(text); actual != expected {
+			t.Fatalf("Expected %d tokens for %q, got %d", expected, text, actual)
+		}
+		if actual := tokens.Count("some-model", text); actual != expected {
+			t.Fatalf("Expected the tokens package to count %d tokens for %q, got %d", expected, text, actual)
+		}
+	}
+}
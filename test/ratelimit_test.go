@@ -0,0 +1,39 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"golang.org/x/time/rate"
+	"testing"
+	"time"
+)
+
+func TestModelRateLimiters(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{
+		RateLimiter: rate.NewLimiter(rate.Inf, 1),
+		ModelRateLimiters: map[string]*rate.Limiter{
+			"limited": rate.NewLimiter(rate.Every(time.Hour), 1),
+		},
+	})
+
+	embed := func(model string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: model, Input: "text"})
+		return err
+	}
+
+	if err := embed("limited"); err != nil {
+		t.Fatalf("First request to the limited model failed with error %v", err)
+	}
+	if err := embed("limited"); err == nil {
+		t.Fatalf("Expected second request to the limited model to be throttled")
+	}
+	for i := 0; i < 3; i++ {
+		if err := embed("unlimited"); err != nil {
+			t.Fatalf("Request to the unlimited model failed with error %v", err)
+		}
+	}
+}
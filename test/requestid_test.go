@@ -0,0 +1,79 @@
+package test
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// startRequestIdGateway serves the gateway behind a middleware which assigns every request an ID, like the API gateway does.
+func startRequestIdGateway(t *testing.T, gateway *fakeGateway) string {
+	t.Helper()
+
+	var requests atomic.Int32
+	_, handler := apigatewayv1connect.NewAPIGatewayServiceHandler(gateway)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(sdk.RequestIdHeader, fmt.Sprintf("req-%d", requests.Add(1)))
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestRequestId(t *testing.T) {
+	gateway := newChatGateway()
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("bad input"))
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{BaseUrl: startRequestIdGateway(t, gateway)})
+
+	var metadata sdk.CallMetadata
+	ctx := sdk.WithCallMetadata(context.Background(), &metadata)
+	if _, err := client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if metadata.RequestId != "req-1" {
+		t.Fatalf("Expected request ID req-1 in the call metadata, got %q", metadata.RequestId)
+	}
+
+	res, err := client.ChatCompleteStream(ctx, &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	defer res.TokenStream.Close()
+	if res.RequestId != "req-2" || metadata.RequestId != "req-2" {
+		t.Fatalf("Expected request ID req-2 on the stream and in the call metadata, got %q and %q", res.RequestId, metadata.RequestId)
+	}
+
+	_, err = client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+	var methodErr *sdk.MethodError
+	if !errors.As(err, &methodErr) || methodErr.RequestId != "req-3" {
+		t.Fatalf("Expected a MethodError with request ID req-3, got %#v", err)
+	}
+	var apiErr sdk.ApiError
+	if !errors.As(err, &apiErr) || apiErr.RequestId() != "req-3" {
+		t.Fatalf("Expected an ApiError with request ID req-3, got %v", err)
+	}
+}
+
+func TestRequestIdMissing(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{MaxPromptChars: 3})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+	var methodErr *sdk.MethodError
+	if !errors.As(err, &methodErr) || methodErr.RequestId != "" {
+		t.Fatalf("Expected a MethodError without a request ID for a call rejected by the client, got %#v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyGateway fails the first calls for each model with the given codes, in order, and then replies with the model name.
+// It records the model of every call it receives.
+type flakyGateway struct {
+	mu       sync.Mutex
+	failures map[string][]connect.Code
+	calls    []string
+}
+
+func (g *flakyGateway) handle(model string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = append(g.calls, model)
+	if codes := g.failures[model]; len(codes) > 0 {
+		g.failures[model] = codes[1:]
+		return connect.NewError(codes[0], errors.New("transient failure"))
+	}
+	return nil
+}
+
+func (g *flakyGateway) gateway() *fakeGateway {
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			if err := g.handle(req.Msg.Model); err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: req.Msg.Model},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := g.handle(req.Msg.Model); err != nil {
+				return err
+			}
+			return sendChunks(stream, "assistant", "", req.Msg.Model)
+		},
+	}
+}
+
+func TestRetry(t *testing.T) {
+	cases := []struct {
+		name        string
+		failures    []connect.Code
+		maxAttempts int
+		calls       int
+		code        connect.Code
+	}{
+		{"recovers", []connect.Code{connect.CodeUnavailable, connect.CodeDeadlineExceeded}, 3, 3, 0},
+		{"gives up", []connect.Code{connect.CodeUnavailable, connect.CodeUnavailable}, 2, 2, connect.CodeUnavailable},
+		{"not retryable", []connect.Code{connect.CodeInvalidArgument}, 3, 1, connect.CodeInvalidArgument},
+		{"disabled", []connect.Code{connect.CodeUnavailable}, 0, 1, connect.CodeUnavailable},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			flaky := &flakyGateway{failures: map[string][]connect.Code{"model": c.failures}}
+			client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+				Retry: &sdk.RetryPolicy{MaxAttempts: c.maxAttempts, InitialBackoff: time.Millisecond},
+			})
+
+			_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+			if (c.code == 0 && err != nil) || (c.code != 0 && connect.CodeOf(err) != c.code) {
+				t.Fatalf("Expected error code %v, got %v", c.code, err)
+			}
+			if len(flaky.calls) != c.calls {
+				t.Fatalf("Expected %d calls, got %d", c.calls, len(flaky.calls))
+			}
+		})
+	}
+}
+
+func TestRetryBeforeFallback(t *testing.T) {
+	flaky := &flakyGateway{failures: map[string][]connect.Code{
+		"model": {connect.CodeUnavailable, connect.CodeUnavailable, connect.CodeUnavailable},
+	}}
+	var attempts []int
+	client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+		Retry:          &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		FallbackModels: []string{"backup"},
+		RewriteOnRetry: func(req any, attempt int, lastErr error) any {
+			attempts = append(attempts, attempt)
+			return nil
+		},
+	})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	tokens, err := res.TokenStream.ReadAll()
+	if err != nil || len(tokens) != 1 || tokens[0] != "backup" {
+		t.Fatalf("Expected the backup model to serve the stream, got %q (error %v)", tokens, err)
+	}
+
+	if calls := strings.Join(flaky.calls, ","); calls != "model,model,backup" {
+		t.Fatalf("Expected calls to model, model and backup, got %s", calls)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatalf("Expected RewriteOnRetry to be called for attempts 1 and 2, got %v", attempts)
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	flaky := &flakyGateway{failures: map[string][]connect.Code{"model": {connect.CodeUnavailable}}}
+	client := newTestClient(t, flaky.gateway(), sdk.ClientOptions{
+		Retry: &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the context error while waiting to retry, got %v", err)
+	}
+	if len(flaky.calls) != 1 {
+		t.Fatalf("Expected a single call, got %d", len(flaky.calls))
+	}
+}
+
+// Returns a gateway which rejects the first ChatComplete call with a rate limit error carrying the given headers, and then succeeds.
+func newRateLimitedGateway(header map[string]string) (*fakeGateway, *int) {
+	calls := 0
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			calls++
+			if calls == 1 {
+				err := connect.NewError(connect.CodeResourceExhausted, errors.New("too many requests"))
+				for key, value := range header {
+					err.Meta().Set(key, value)
+				}
+				return nil, err
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{}), nil
+		},
+	}, &calls
+}
+
+func TestRateLimitError(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	gateway, _ := newRateLimitedGateway(map[string]string{
+		"Retry-After":           "30",
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+	})
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	_, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+
+	var rateLimitErr *sdk.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if rateLimitErr.RetryAfter != 30*time.Second || rateLimitErr.Limit != 100 || rateLimitErr.Remaining != 0 || !rateLimitErr.ResetAt.Equal(reset) {
+		t.Fatalf("Expected a 30s delay, a limit of 100 with none remaining, resetting at %v, got %+v", reset, rateLimitErr)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	gateway, calls := newRateLimitedGateway(map[string]string{"Retry-After": "1"})
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		Retry: &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+
+	start := time.Now()
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Expected the retry to wait for Retry-After, waited %v", elapsed)
+	}
+	if *calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", *calls)
+	}
+}
+
+func TestRetryAfterPastDeadline(t *testing.T) {
+	gateway, calls := newRateLimitedGateway(map[string]string{"Retry-After": "60"})
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		Retry: &sdk.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := client.ChatComplete(ctx, &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	var rateLimitErr *sdk.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || *calls != 1 {
+		t.Fatalf("Expected the call to fail without retrying, got %d calls in %v", *calls, elapsed)
+	}
+}
+
+func TestRetryInvalidPolicy(t *testing.T) {
+	for _, policy := range []sdk.RetryPolicy{{MaxAttempts: 3, Multiplier: 0.5}, {MaxAttempts: 3, Jitter: 2}} {
+		if _, err := sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", Retry: &policy}); err == nil {
+			t.Fatalf("Expected NewClient to fail for policy %+v", policy)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestModalityBaseUrls(t *testing.T) {
+	chatUrl := startGateway(t, newChatGateway())
+	embedUrl := startGateway(t, newEmbedGateway())
+	defaultUrl := startGateway(t, &fakeGateway{
+		transcribe: func(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+			return connect.NewResponse(&apigatewayv1.TranscribeResponse{Text: "default gateway"}), nil
+		},
+	})
+
+	client := newTestClient(t, nil, sdk.ClientOptions{
+		BaseUrl:      defaultUrl,
+		ChatBaseUrl:  chatUrl,
+		EmbedBaseUrl: embedUrl,
+	})
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("Expected ChatComplete to reach the chat gateway, got error %v", err)
+	}
+	stream, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("Expected ChatCompleteStream to reach the chat gateway, got error %v", err)
+	}
+	stream.TokenStream.Close()
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+		t.Fatalf("Expected Embed to reach the embed gateway, got error %v", err)
+	}
+
+	res, err := client.Transcribe(context.Background(), &apigatewayv1.TranscribeRequest{Model: "model"})
+	if err != nil || res.Text != "default gateway" {
+		t.Fatalf("Expected Transcribe to fall back to BaseUrl, got %v and error %v", res, err)
+	}
+}
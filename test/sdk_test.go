@@ -1,6 +1,9 @@
 package test
 
 import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
 	"errors"
 	sdk "github.com/fxnlabs/function-go-sdk"
 	"testing"
@@ -33,3 +36,68 @@ func TestCreateClientWithoutApiKey(t *testing.T) {
 		t.Fatalf("Expected nil client")
 	}
 }
+
+func TestNilRequests(t *testing.T) {
+	client, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKey: "mykey",
+	})
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"ChatComplete": func() error {
+			_, err := client.ChatComplete(ctx, nil)
+			return err
+		},
+		"ChatCompleteStream": func() error {
+			_, err := client.ChatCompleteStream(ctx, nil)
+			return err
+		},
+		"Embed": func() error {
+			_, err := client.Embed(ctx, nil)
+			return err
+		},
+		"TextToImage": func() error {
+			_, err := client.TextToImage(ctx, nil)
+			return err
+		},
+		"Transcribe": func() error {
+			_, err := client.Transcribe(ctx, nil)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, sdk.NilRequestError) {
+			t.Errorf("%s: expected NilRequestError, got %v", name, err)
+		}
+	}
+}
+
+func TestConnectOptions(t *testing.T) {
+	var gotHeader string
+	gateway := &fakeGateway{
+		embed: func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+			gotHeader = req.Header().Get("x-custom")
+			return connect.NewResponse(&apigatewayv1.EmbedResponse{}), nil
+		},
+	}
+	headerInterceptor := connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			req.Header().Set("x-custom", "value")
+			return next(ctx, req)
+		}
+	})
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		ConnectOptions: []connect.ClientOption{connect.WithInterceptors(headerInterceptor)},
+	})
+
+	if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if gotHeader != "value" {
+		t.Fatalf("Expected the custom Connect option to be applied")
+	}
+}
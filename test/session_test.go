@@ -0,0 +1,215 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"slices"
+	"testing"
+)
+
+// newEchoHistoryGateway creates a gateway which replies with the number of messages of the request and the content of the last one.
+// It fails chat completions whose last message is "fail", and ends streams with an error in that case.
+func newEchoHistoryGateway() *fakeGateway {
+	reply := func(messages []*apigatewayv1.ChatCompleteMessage) (string, error) {
+		last := messages[len(messages)-1].Content
+		if last == "fail" {
+			return "", connect.NewError(connect.CodeInternal, errors.New("failed"))
+		}
+		return fmt.Sprintf("%d:%s", len(messages), last), nil
+	}
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			content, err := reply(req.Msg.Message)
+			if err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: content},
+			}), nil
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			content, err := reply(req.Msg.Message)
+			if err != nil {
+				sendChunks(stream, "assistant", "", "partial")
+				return err
+			}
+			return sendChunks(stream, "assistant", "", content[:1], content[1:])
+		},
+	}
+}
+
+func TestChatSession(t *testing.T) {
+	client := newTestClient(t, newEchoHistoryGateway(), sdk.ClientOptions{})
+	session := sdk.NewChatSession(client, sdk.ChatSessionOptions{Model: "model", SystemPrompt: "Be brief"})
+
+	reply, err := session.Send(context.Background(), "Hello")
+	if err != nil || reply != "2:Hello" {
+		t.Fatalf("Expected reply %q, got %q and error %v", "2:Hello", reply, err)
+	}
+
+	res, err := session.SendStream(context.Background(), "Again")
+	if err != nil {
+		t.Fatalf("SendStream failed with error %v", err)
+	}
+	if len(session.History()) != 2 {
+		t.Fatalf("Expected the streamed reply to be added to the history only once read, got %v", session.History())
+	}
+	if _, err := res.TokenStream.ReadAll(); err != nil {
+		t.Fatalf("ReadAll failed with error %v", err)
+	}
+
+	expected := []sdk.Message{
+		sdk.UserMessage("Hello"), sdk.AssistantMessage("2:Hello"),
+		sdk.UserMessage("Again"), sdk.AssistantMessage("4:Again"),
+	}
+	if history := session.History(); !slices.Equal(history, expected) {
+		t.Fatalf("Expected history %v, got %v", expected, history)
+	}
+
+	session.Reset()
+	if reply, _ := session.Send(context.Background(), "Restart"); reply != "2:Restart" {
+		t.Fatalf("Expected the history to be forgotten, got reply %q", reply)
+	}
+}
+
+func TestChatSessionFailure(t *testing.T) {
+	client := newTestClient(t, newEchoHistoryGateway(), sdk.ClientOptions{})
+	session := sdk.NewChatSession(client, sdk.ChatSessionOptions{
+		Model:   "model",
+		History: []sdk.Message{sdk.UserMessage("Hello"), sdk.AssistantMessage("Hi")},
+	})
+
+	if _, err := session.Send(context.Background(), "fail"); err == nil {
+		t.Fatalf("Expected Send to fail")
+	}
+
+	res, err := session.SendStream(context.Background(), "fail")
+	if err != nil {
+		t.Fatalf("SendStream failed with error %v", err)
+	}
+	if _, err := res.TokenStream.ReadAll(); err == nil {
+		t.Fatalf("Expected the stream to fail")
+	}
+
+	res, err = session.SendStream(context.Background(), "Closed")
+	if err != nil {
+		t.Fatalf("SendStream failed with error %v", err)
+	}
+	res.TokenStream.Close()
+
+	if history := session.History(); len(history) != 2 {
+		t.Fatalf("Expected failed and closed sends to leave the history unchanged, got %v", history)
+	}
+}
+
+func TestChatSessionHistoryStrategies(t *testing.T) {
+	tests := []struct {
+		strategy sdk.HistoryStrategy
+		reply    string
+		history  int
+	}{
+		// With one token per message, a budget of 4 leaves room for 3 messages of history along with the new message,
+		// so only the last turn of the history fits.
+		{strategy: sdk.HistoryKeepAll, reply: "5:c", history: 6},
+		{strategy: sdk.HistorySlidingWindow, reply: "3:c", history: 6},
+		{strategy: sdk.HistoryDropOldest, reply: "3:c", history: 4},
+		// Half of the room does not fit the last turn either, so every turn is summarized.
+		{strategy: sdk.HistorySummarize, reply: "2:c", history: 3},
+	}
+	for _, test := range tests {
+		client := newTestClient(t, newEchoHistoryGateway(), sdk.ClientOptions{})
+		session := sdk.NewChatSession(client, sdk.ChatSessionOptions{
+			Model:            "model",
+			HistoryStrategy:  test.strategy,
+			MaxContextTokens: 4,
+			CountTokens:      func(string) int { return 1 },
+		})
+
+		for _, text := range []string{"a", "b"} {
+			if _, err := session.Send(context.Background(), text); err != nil {
+				t.Fatalf("Send failed with error %v", err)
+			}
+		}
+		reply, err := session.Send(context.Background(), "c")
+		if err != nil {
+			t.Fatalf("Send failed with error %v", err)
+		}
+		if reply != test.reply {
+			t.Fatalf("Expected reply %q with strategy %d, got %q", test.reply, test.strategy, reply)
+		}
+		if history := session.History(); len(history) != test.history {
+			t.Fatalf("Expected %d messages of history with strategy %d, got %v", test.history, test.strategy, history)
+		}
+	}
+}
+
+func TestChatSessionSummary(t *testing.T) {
+	gateway := newEchoHistoryGateway()
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		ModelLimits: map[string]sdk.ModelLimits{"model": {MaxInputTokens: 4}},
+	})
+	session := sdk.NewChatSession(client, sdk.ChatSessionOptions{
+		Model:           "model",
+		HistoryStrategy: sdk.HistorySummarize,
+		History:         []sdk.Message{sdk.UserMessage("Hello"), sdk.AssistantMessage("Hi")},
+		CountTokens:     func(string) int { return 1 },
+	})
+
+	if _, err := session.Send(context.Background(), "Continue"); err != nil {
+		t.Fatalf("Send failed with error %v", err)
+	}
+	if _, err := session.Send(context.Background(), "Again"); err != nil {
+		t.Fatalf("Send failed with error %v", err)
+	}
+
+	// The summary replies with the number of messages of the summary request, and the transcript.
+	history := session.History()
+	expected := sdk.SystemMessage(sdk.SummaryPrefix + "2:user: Hello\n\nassistant: Hi\n\nuser: Continue\n\nassistant: 3:Continue\n\n")
+	if len(history) != 3 || history[0] != expected {
+		t.Fatalf("Expected the history to start with summary %q, got %v", expected.Content, history)
+	}
+}
+
+func TestChatSessionShortenOnSuccess(t *testing.T) {
+	gateway := newEchoHistoryGateway()
+	chatComplete := gateway.chatComplete
+	var tenants []string
+	gateway.chatComplete = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+		tenants = append(tenants, req.Header().Get("X-Tenant"))
+		return chatComplete(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+	history := []sdk.Message{sdk.UserMessage("a"), sdk.AssistantMessage("1"), sdk.UserMessage("b"), sdk.AssistantMessage("2")}
+
+	for _, strategy := range []sdk.HistoryStrategy{sdk.HistoryDropOldest, sdk.HistorySummarize} {
+		tenants = nil
+		session := sdk.NewChatSession(client, sdk.ChatSessionOptions{
+			Model:            "model",
+			History:          history,
+			HistoryStrategy:  strategy,
+			MaxContextTokens: 4,
+			CountTokens:      func(string) int { return 1 },
+		})
+
+		if _, err := session.Send(context.Background(), "fail", sdk.WithCallHeader("X-Tenant", "acme")); err == nil {
+			t.Fatalf("Expected Send to fail")
+		}
+		if !slices.Equal(session.History(), history) {
+			t.Fatalf("Expected a failed send to leave the history unchanged with strategy %d, got %v", strategy, session.History())
+		}
+		if len(tenants) == 0 || slices.ContainsFunc(tenants, func(tenant string) bool { return tenant != "acme" }) {
+			t.Fatalf("Expected every call to have the options of the send with strategy %d, got %v", strategy, tenants)
+		}
+
+		if _, err := session.Send(context.Background(), "c"); err != nil {
+			t.Fatalf("Send failed with error %v", err)
+		}
+		if len(session.History()) >= len(history)+2 {
+			t.Fatalf("Expected a successful send to shorten the history with strategy %d, got %v", strategy, session.History())
+		}
+	}
+}
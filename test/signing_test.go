@@ -0,0 +1,110 @@
+package test
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"connectrpc.com/connect"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// startVerifyingGateway serves the gateway behind a middleware which verifies the HMAC signature of every request
+// over its raw body, before it is decoded, as a gateway requiring signatures does. It records whether each procedure's request was valid.
+func startVerifyingGateway(t *testing.T, gateway *fakeGateway, secret []byte) (string, func(procedure string) bool) {
+	t.Helper()
+
+	var mu sync.Mutex
+	verified := map[string]bool{}
+	_, handler := apigatewayv1connect.NewAPIGatewayServiceHandler(gateway)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(r.URL.Path + "\n" + r.Header.Get(sdk.SignatureTimestampHeader) + "\n"))
+		mac.Write(body)
+		signature, err := hex.DecodeString(r.Header.Get(sdk.SignatureHeader))
+
+		mu.Lock()
+		verified[r.URL.Path] = err == nil && hmac.Equal(signature, mac.Sum(nil))
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, func(procedure string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return verified[procedure]
+	}
+}
+
+func TestHmacSigner(t *testing.T) {
+	secret := []byte("secret")
+	gateway := newEmbedGateway()
+	gateway.chatCompleteStream = newChatGateway().chatCompleteStream
+
+	// The signature covers the body as sent, whatever the codec and compression.
+	for _, options := range []sdk.ClientOptions{
+		{},
+		{Codec: sdk.CodecJson, Compression: sdk.CompressionGzip},
+	} {
+		baseUrl, verified := startVerifyingGateway(t, gateway, secret)
+		options.BaseUrl = baseUrl
+		options.RequestSigner = sdk.NewHmacSigner(secret)
+		client := newTestClient(t, gateway, options)
+
+		if _, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+			t.Fatalf("Embed failed with error %v", err)
+		}
+		res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+		if err != nil {
+			t.Fatalf("ChatCompleteStream failed with error %v", err)
+		}
+		if _, err := res.TokenStream.ReadAll(); err != nil {
+			t.Fatalf("ReadAll failed with error %v", err)
+		}
+
+		if !verified(apigatewayv1connect.APIGatewayServiceEmbedProcedure) || !verified(apigatewayv1connect.APIGatewayServiceChatCompleteStreamProcedure) {
+			t.Fatalf("Expected valid signatures with codec %v and compression %q", options.Codec, options.Compression)
+		}
+	}
+}
+
+func TestRequestSignerError(t *testing.T) {
+	signErr := errors.New("signing key unavailable")
+	var requests int
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		requests++
+		return embed(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{
+		RequestSigner: func(procedure string, body []byte, header http.Header) error {
+			if !strings.HasSuffix(procedure, "/Embed") {
+				t.Errorf("Unexpected procedure %q", procedure)
+			}
+			return signErr
+		},
+	})
+
+	_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+	if !errors.Is(err, signErr) {
+		t.Fatalf("Expected the signer error, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("Expected the request not to be sent")
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+)
+
+func TestSmokeTest(t *testing.T) {
+	gateway := newChatGateway()
+	gateway.embed = newEmbedGateway().embed
+	gateway.textToImage = func(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("model is down"))
+	}
+	gateway.transcribe = func(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+		return connect.NewResponse(&apigatewayv1.TranscribeResponse{Text: "heard " + req.Msg.Url}), nil
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+	options := &sdk.SmokeTestOptions{AudioUrl: "https://example.com/hello.wav"}
+
+	cases := []struct {
+		modality sdk.Modality
+		success  bool
+		sample   string
+	}{
+		{sdk.ModalityChat, true, "Hi there"},
+		{sdk.ModalityEmbed, true, "4 dimensions"},
+		{sdk.ModalityImage, false, ""},
+		{sdk.ModalityTranscribe, true, "heard https://example.com/hello.wav"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.modality), func(t *testing.T) {
+			result, err := client.SmokeTest(context.Background(), "model", c.modality, options)
+			if (err == nil) != c.success {
+				t.Fatalf("Expected success %v, got error %v", c.success, err)
+			}
+			if result == nil || result.Success != c.success || result.Sample != c.sample || result.Modality != c.modality {
+				t.Fatalf("Unexpected result %+v", result)
+			}
+			if result.Latency <= 0 {
+				t.Fatalf("Expected a positive latency")
+			}
+		})
+	}
+}
+
+func TestSmokeTestMissingAudio(t *testing.T) {
+	client := newTestClient(t, &fakeGateway{}, sdk.ClientOptions{})
+
+	result, err := client.SmokeTest(context.Background(), "model", sdk.ModalityTranscribe, nil)
+	if result != nil || !errors.Is(err, sdk.MissingSmokeTestAudioError) {
+		t.Fatalf("Expected MissingSmokeTestAudioError, got %v and %v", result, err)
+	}
+}
+
+func TestSmokeTestEmptyResponse(t *testing.T) {
+	gateway := &fakeGateway{
+		textToImage: func(ctx context.Context, req *connect.Request[apigatewayv1.TextToImageRequest]) (*connect.Response[apigatewayv1.TextToImageResponse], error) {
+			return connect.NewResponse(&apigatewayv1.TextToImageResponse{}), nil
+		},
+		transcribe: func(ctx context.Context, req *connect.Request[apigatewayv1.TranscribeRequest]) (*connect.Response[apigatewayv1.TranscribeResponse], error) {
+			return connect.NewResponse(&apigatewayv1.TranscribeResponse{}), nil
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+	options := &sdk.SmokeTestOptions{AudioUrl: "https://example.com/hello.wav"}
+
+	for _, modality := range []sdk.Modality{sdk.ModalityImage, sdk.ModalityTranscribe} {
+		t.Run(string(modality), func(t *testing.T) {
+			result, err := client.SmokeTest(context.Background(), "model", modality, options)
+			if !errors.Is(err, sdk.EmptySmokeTestResponseError) {
+				t.Fatalf("Expected EmptySmokeTestResponseError, got %v", err)
+			}
+			if result == nil || result.Success || result.Sample != "" {
+				t.Fatalf("Unexpected result %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSplitTokenStream(t *testing.T) {
+	cases := []struct {
+		name     string
+		tokens   []string
+		expected []string
+	}{
+		{"whole delimiter", []string{"think", "<sep>", "answer"}, []string{"think", "answer"}},
+		{"delimiter within a token", []string{"think<sep>answer"}, []string{"think", "answer"}},
+		{"delimiter straddling tokens", []string{"think<s", "ep>ans", "wer"}, []string{"think", "answer"}},
+		{"delimiter split per character", []string{"a", "<", "s", "e", "p", ">", "b"}, []string{"a", "b"}},
+		{"false start", []string{"a<s", "ip<sep", ">b"}, []string{"a<sip", "b"}},
+		{"partial delimiter at the end", []string{"a<se"}, []string{"a<se"}},
+		{"consecutive delimiters", []string{"a<sep><s", "ep>b"}, []string{"a", "", "b"}},
+		{"no delimiter", []string{"a", "b"}, []string{"ab"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sections, err := sdk.SplitTokenStream(sdk.StaticChatCompleteStream("assistant", c.tokens, nil), "<sep>").Sections()
+			if err != nil {
+				t.Fatalf("Sections failed with error %v", err)
+			}
+			if !reflect.DeepEqual(sections, c.expected) {
+				t.Fatalf("Expected sections %q, got %q", c.expected, sections)
+			}
+		})
+	}
+}
+
+func TestSplitTokenStreamEvents(t *testing.T) {
+	stream := sdk.SplitTokenStream(sdk.StaticChatCompleteStream("assistant", []string{"ab<", "sep>c"}, nil), "<sep>")
+
+	var tokens []sdk.SectionToken
+	for {
+		token, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed with error %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	expected := []sdk.SectionToken{{Section: 0, Text: "ab"}, {Section: 1, Text: "c"}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Fatalf("Expected tokens %v, got %v", expected, tokens)
+	}
+}
+
+func TestSplitTokenStreamError(t *testing.T) {
+	failure := errors.New("stream failed")
+	stream := sdk.SplitTokenStream(sdk.StaticChatCompleteStream("assistant", []string{"a<se"}, failure), "<sep>")
+
+	sections, err := stream.Sections()
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the stream error, got %v", err)
+	}
+	if !reflect.DeepEqual(sections, []string{"a<se"}) {
+		t.Fatalf("Expected held back text to be returned before the error, got %q", sections)
+	}
+}
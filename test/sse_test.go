@@ -0,0 +1,82 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamSSE(t *testing.T) {
+	client := newTestClient(t, newStreamGateway("Hello", ",\nworld"), sdk.ClientOptions{})
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := sdk.StreamSSE(context.Background(), recorder, res, sdk.SSEOptions{}); err != nil {
+		t.Fatalf("StreamSSE failed with error %v", err)
+	}
+
+	expected := "event: token\ndata: Hello\n\nevent: token\ndata: ,\ndata: world\n\nevent: done\ndata: \n\n"
+	if recorder.Body.String() != expected {
+		t.Fatalf("Expected events %q, got %q", expected, recorder.Body.String())
+	}
+	if recorder.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream content type, got %q", recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestStreamSSEHeartbeat(t *testing.T) {
+	gateway := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Hello"); err != nil {
+				return err
+			}
+			time.Sleep(200 * time.Millisecond)
+			return sendChunks(stream, "assistant", "world")
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := sdk.StreamSSE(context.Background(), recorder, res, sdk.SSEOptions{HeartbeatInterval: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("StreamSSE failed with error %v", err)
+	}
+
+	body := recorder.Body.String()
+	before, after, found := strings.Cut(body, "data: Hello\n\n")
+	if !found || before != "event: token\n" {
+		t.Fatalf("Expected the first token to be written before any heartbeat, got %q", body)
+	}
+	stall, rest, _ := strings.Cut(after, "event: token\ndata: world\n\n")
+	if strings.Count(stall, sdk.SSEHeartbeat) < 2 || strings.ReplaceAll(stall, sdk.SSEHeartbeat, "") != "" {
+		t.Fatalf("Expected only heartbeats during the stall, got %q", stall)
+	}
+	if rest != "event: done\ndata: \n\n" {
+		t.Fatalf("Expected no heartbeats after completion, got %q", rest)
+	}
+}
+
+func TestStreamSSEError(t *testing.T) {
+	failure := errors.New("stream failed")
+	recorder := httptest.NewRecorder()
+
+	err := sdk.StreamSSE(context.Background(), recorder, sdk.StaticChatCompleteStream("assistant", []string{"a"}, failure), sdk.SSEOptions{})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the stream error, got %v", err)
+	}
+	if !strings.HasSuffix(recorder.Body.String(), "event: error\ndata: ChatCompleteStream: stream failed\n\n") {
+		t.Fatalf("Expected an error event, got %q", recorder.Body.String())
+	}
+}
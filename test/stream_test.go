@@ -0,0 +1,164 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newStreamGateway creates a gateway which streams a role-only header chunk followed by the given tokens.
+func newStreamGateway(tokens ...string) *fakeGateway {
+	return &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			return sendChunks(stream, "assistant", append([]string{""}, tokens...)...)
+		},
+	}
+}
+
+func TestStreamHasStarted(t *testing.T) {
+	client := newTestClient(t, newStreamGateway("Hello"), sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if res.HasStarted() {
+		t.Fatalf("Expected stream not to have started before reading")
+	}
+
+	if _, err := res.TokenStream.Read(); err != nil {
+		t.Fatalf("Read failed with error %v", err)
+	}
+	if !res.HasStarted() {
+		t.Fatalf("Expected stream to have started after reading a token")
+	}
+	if res.Elapsed() <= 0 {
+		t.Fatalf("Expected a positive elapsed time")
+	}
+}
+
+func TestStreamErrAfterClose(t *testing.T) {
+	gateway := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Hello"); err != nil {
+				return err
+			}
+			return connect.NewError(connect.CodeDataLoss, errors.New("lost it"))
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if _, err := res.TokenStream.Read(); err != nil {
+		t.Fatalf("Read failed with error %v", err)
+	}
+	if res.TokenStream.Err() != nil {
+		t.Fatalf("Expected no error before the stream failed")
+	}
+
+	if _, err := res.TokenStream.Read(); connect.CodeOf(err) != connect.CodeDataLoss {
+		t.Fatalf("Expected the server error from Read, got %v", err)
+	}
+	res.TokenStream.Close()
+
+	if _, err := res.TokenStream.Read(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Expected io.EOF after the error was reported, got %v", err)
+	}
+	if connect.CodeOf(res.TokenStream.Err()) != connect.CodeDataLoss {
+		t.Fatalf("Expected Err to report the server error after Close, got %v", res.TokenStream.Err())
+	}
+}
+
+func TestStreamAppendAll(t *testing.T) {
+	client := newTestClient(t, newStreamGateway("Hello", ", ", "world"), sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+
+	buffer := make([]string, 1, 8)
+	tokens, err := res.TokenStream.AppendAll(buffer)
+	if err != nil {
+		t.Fatalf("AppendAll failed with error %v", err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"", "Hello", ", ", "world"}) {
+		t.Fatalf("Unexpected tokens %q", tokens)
+	}
+	if &tokens[0] != &buffer[0] {
+		t.Fatalf("Expected the buffer to be reused")
+	}
+}
+
+func TestStreamReadAllError(t *testing.T) {
+	failure := errors.New("stream failed")
+	tokens, err := sdk.StaticChatCompleteStream("assistant", []string{"a", "b"}, failure).TokenStream.ReadAll()
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the stream error, got %v", err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"a", "b"}) {
+		t.Fatalf("Expected the tokens read before the error, got %q", tokens)
+	}
+}
+
+func BenchmarkStreamAppendAll(b *testing.B) {
+	tokens := make([]string, 256)
+	for i := range tokens {
+		tokens[i] = "token"
+	}
+
+	b.Run("ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sdk.StaticChatCompleteStream("assistant", tokens, nil).TokenStream.ReadAll()
+		}
+	})
+
+	b.Run("AppendAll", func(b *testing.B) {
+		b.ReportAllocs()
+		buffer := make([]string, 0, len(tokens))
+		for range b.N {
+			buffer, _ = sdk.StaticChatCompleteStream("assistant", tokens, nil).TokenStream.AppendAll(buffer[:0])
+		}
+	})
+}
+
+func TestStreamFirstTokenLatency(t *testing.T) {
+	gateway := &fakeGateway{
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", ""); err != nil {
+				return err
+			}
+			time.Sleep(100 * time.Millisecond)
+			return sendChunks(stream, "assistant", "Hello", ", ", "world")
+		},
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if res.FirstTokenLatency() != 0 || res.TokensPerSecond() != 0 {
+		t.Fatalf("Expected no timings before reading")
+	}
+
+	if _, err := res.TokenStream.ReadAll(); err != nil {
+		t.Fatalf("ReadAll failed with error %v", err)
+	}
+	if latency := res.FirstTokenLatency(); latency < 100*time.Millisecond || latency > res.Elapsed() {
+		t.Fatalf("Expected the first token latency to include the delay, got %v", latency)
+	}
+	if res.TokensPerSecond() <= 0 {
+		t.Fatalf("Expected a positive token rate, got %v", res.TokensPerSecond())
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"encoding/json"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"strings"
+	"testing"
+)
+
+type city struct {
+	Name       string   `json:"name" description:"The name of the city"`
+	Population int      `json:"population"`
+	Landmarks  []string `json:"landmarks,omitempty"`
+}
+
+// newRepliesGateway creates a gateway which replies to chat completions with the given contents in turn, and records the requests.
+func newRepliesGateway(requests *[]*apigatewayv1.ChatCompleteRequest, replies ...string) *fakeGateway {
+	return &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			*requests = append(*requests, req.Msg)
+			content := replies[min(len(*requests), len(replies))-1]
+			return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+				Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: content},
+			}), nil
+		},
+	}
+}
+
+func TestChatCompleteJson(t *testing.T) {
+	var requests []*apigatewayv1.ChatCompleteRequest
+	gateway := newRepliesGateway(&requests,
+		`{"name": "Paris"}`,
+		"Here it is:\n```json\n{\"name\": \"Paris\", \"population\": 2100000}\n```",
+	)
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	result, err := sdk.ChatCompleteJson[city](context.Background(), client, &apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: sdk.Messages(sdk.UserMessage("Describe the capital of France")),
+	})
+	if err != nil {
+		t.Fatalf("ChatCompleteJson failed with error %v", err)
+	}
+	if result.Name != "Paris" || result.Population != 2100000 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected the invalid reply to be retried once, got %d requests", len(requests))
+	}
+	if first := requests[0].Message[0]; first.Role != "system" || !strings.Contains(first.Content, `"population":{"type":"integer"}`) {
+		t.Fatalf("Expected the first message to describe the schema, got %v", first)
+	}
+	retry := requests[1].Message
+	if len(retry) != 4 || !strings.Contains(retry[3].Content, `missing required property "population"`) {
+		t.Fatalf("Expected the retry to explain why the reply was rejected, got %v", retry)
+	}
+}
+
+func TestChatCompleteJsonInvalid(t *testing.T) {
+	var requests []*apigatewayv1.ChatCompleteRequest
+	client := newTestClient(t, newRepliesGateway(&requests, `{"name": 42, "population": 1}`), sdk.ClientOptions{})
+
+	_, err := sdk.ChatCompleteJson[city](context.Background(), client, &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	var invalidErr *sdk.InvalidJsonResponseError
+	if !errors.As(err, &invalidErr) || invalidErr.Content != `{"name": 42, "population": 1}` {
+		t.Fatalf("Expected an InvalidJsonResponseError, got %v", err)
+	}
+	if len(requests) != sdk.JsonAttempts {
+		t.Fatalf("Expected %d requests, got %d", sdk.JsonAttempts, len(requests))
+	}
+
+	if _, err := sdk.ChatCompleteJson[city](context.Background(), client, nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError, got %v", err)
+	}
+}
+
+func TestJsonSchemaOf(t *testing.T) {
+	schema, err := json.Marshal(sdk.JsonSchemaOf[map[string]*city]())
+	if err != nil {
+		t.Fatalf("Marshal failed with error %v", err)
+	}
+
+	expected := `{"additionalProperties":{"properties":{"landmarks":{"items":{"type":"string"},"type":["array","null"]},` +
+		`"name":{"description":"The name of the city","type":"string"},"population":{"type":"integer"}},` +
+		`"required":["name","population"],"type":["object","null"]},"type":["object","null"]}`
+	if string(schema) != expected {
+		t.Fatalf("Expected schema %s, got %s", expected, schema)
+	}
+}
+
+func TestChatCompleteJsonScalar(t *testing.T) {
+	var requests []*apigatewayv1.ChatCompleteRequest
+	client := newTestClient(t, newRepliesGateway(&requests, "forty-two", "```json\n42\n```"), sdk.ClientOptions{})
+
+	result, err := sdk.ChatCompleteJson[int](context.Background(), client, &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteJson failed with error %v", err)
+	}
+	if result != 42 || len(requests) != 2 {
+		t.Fatalf("Expected 42 after 2 requests, got %d after %d", result, len(requests))
+	}
+}
+
+type label struct {
+	Label string `json:"label"`
+}
+
+type node struct {
+	*node
+	label
+	Value int `json:"value"`
+}
+
+func TestJsonSchemaOfEmbedded(t *testing.T) {
+	schema, err := json.Marshal(sdk.JsonSchemaOf[node]())
+	if err != nil {
+		t.Fatalf("Marshal failed with error %v", err)
+	}
+
+	expected := `{"properties":{"label":{"type":"string"},"value":{"type":"integer"}},"required":["label","value"],"type":"object"}`
+	if string(schema) != expected {
+		t.Fatalf("Expected schema %s, got %s", expected, schema)
+	}
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+	"time"
+)
+
+// newPacedGateway creates a gateway which streams the given tokens, waiting for the matching delay before each one,
+// and whose unary methods take the given delay.
+func newPacedGateway(delay time.Duration, tokens []string, delays []time.Duration) *fakeGateway {
+	gateway := newEmbedGateway()
+	embed := gateway.embed
+	gateway.embed = func(ctx context.Context, req *connect.Request[apigatewayv1.EmbedRequest]) (*connect.Response[apigatewayv1.EmbedResponse], error) {
+		time.Sleep(delay)
+		return embed(ctx, req)
+	}
+	gateway.chatComplete = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+		time.Sleep(delay)
+		return connect.NewResponse(&apigatewayv1.ChatCompleteResponse{
+			Response: &apigatewayv1.ChatCompleteMessage{Role: "assistant", Content: "done"},
+		}), nil
+	}
+	gateway.chatCompleteStream = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+		if err := sendChunks(stream, "assistant", ""); err != nil {
+			return err
+		}
+		for i, token := range tokens {
+			select {
+			case <-time.After(delays[i]):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if err := sendChunks(stream, "assistant", token); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return gateway
+}
+
+func TestMethodTimeouts(t *testing.T) {
+	client := newTestClient(t, newPacedGateway(50*time.Millisecond, nil, nil), sdk.ClientOptions{
+		Timeout:  time.Second,
+		Timeouts: sdk.Timeouts{Methods: map[string]time.Duration{"Embed": 10 * time.Millisecond}},
+	})
+
+	_, err := client.Embed(context.Background(), &apigatewayv1.EmbedRequest{Model: "model", Input: "text"})
+	if connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+		t.Fatalf("Expected the Embed timeout to apply, got %v", err)
+	}
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("Expected the default timeout to apply to ChatComplete, got %v", err)
+	}
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	tokens := []string{"a", "b", "c", "d", "e"}
+	steady := []time.Duration{20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond}
+	stalled := []time.Duration{0, 0, time.Second, 0, 0}
+	options := sdk.ClientOptions{Timeouts: sdk.Timeouts{StreamIdle: 50 * time.Millisecond}}
+
+	// A stream which takes longer than the idle timeout overall, but keeps making progress, completes.
+	client := newTestClient(t, newPacedGateway(0, tokens, steady), options)
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if read, err := res.TokenStream.ReadAll(); err != nil || len(read) != len(tokens) {
+		t.Fatalf("Expected the steady stream to complete, got %q (error %v)", read, err)
+	}
+
+	// A stream which stalls fails once the idle timeout passes.
+	client = newTestClient(t, newPacedGateway(0, tokens, stalled), options)
+	res, err = client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	started := time.Now()
+	read, err := res.TokenStream.ReadAll()
+	if !errors.Is(err, sdk.StreamIdleTimeoutError) || connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+		t.Fatalf("Expected StreamIdleTimeoutError, got %v", err)
+	}
+	if len(read) != 2 || time.Since(started) > 500*time.Millisecond {
+		t.Fatalf("Expected the stream to fail after 2 tokens once idle, got %q after %v", read, time.Since(started))
+	}
+}
+
+func TestTimeoutsInvalidMethod(t *testing.T) {
+	_, err := sdk.NewClient(sdk.ClientOptions{
+		ApiKey:   "mykey",
+		Timeouts: sdk.Timeouts{Methods: map[string]time.Duration{"Embedd": time.Second}},
+	})
+	if err == nil {
+		t.Fatalf("Expected NewClient to fail for an unknown method")
+	}
+}
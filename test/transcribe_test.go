@@ -0,0 +1,40 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"testing"
+	"time"
+)
+
+func TestFormatParagraphs(t *testing.T) {
+	resp := &apigatewayv1.TranscribeResponse{
+		Text: "Hello there. How are you?",
+		Words: []*apigatewayv1.TranscribeResponse_Word{
+			{Word: "Hello", StartSecond: 0, EndSecond: 0.4},
+			{Word: "there.", StartSecond: 0.5, EndSecond: 0.9},
+			{Word: "How", StartSecond: 2.5, EndSecond: 2.7},
+			{Word: "are", StartSecond: 2.8, EndSecond: 2.9},
+			{Word: "you?", StartSecond: 3.0, EndSecond: 3.3},
+		},
+	}
+
+	expected := "Hello there.\n\nHow are you?"
+	if text := sdk.FormatParagraphs(resp, time.Second); text != expected {
+		t.Fatalf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestFormatParagraphsWithoutTimestamps(t *testing.T) {
+	resp := &apigatewayv1.TranscribeResponse{
+		Text: "Hello there.",
+		Words: []*apigatewayv1.TranscribeResponse_Word{
+			{Word: "Hello"},
+			{Word: "there."},
+		},
+	}
+
+	if text := sdk.FormatParagraphs(resp, time.Second); text != resp.Text {
+		t.Fatalf("Expected the raw text, got %q", text)
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"buf.build/gen/go/fxnlabs/api-gateway/connectrpc/go/apigateway/v1/apigatewayv1connect"
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// startTlsGateway starts a gateway served over TLS, which requires its own certificate as client certificate if mutual is true.
+// It returns the gateway URL, and the paths of PEM files holding the gateway's certificate and private key.
+func startTlsGateway(t *testing.T, gateway *fakeGateway, mutual bool) (url string, certFile string, keyFile string) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle(apigatewayv1connect.NewAPIGatewayServiceHandler(gateway))
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// The test certificate is not valid for client authentication, so the client certificate is compared to it instead of verified.
+	cert := server.TLS.Certificates[0]
+	if mutual {
+		server.TLS.ClientAuth = tls.RequireAnyClientCert
+		server.TLS.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if !bytes.Equal(rawCerts[0], cert.Certificate[0]) {
+				return errors.New("unknown client certificate")
+			}
+			return nil
+		}
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatalf("Key encoding failed with error %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatalf("Writing the certificate failed with error %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatalf("Writing the key failed with error %v", err)
+	}
+
+	return server.URL, certFile, keyFile
+}
+
+func TestProxyUrl(t *testing.T) {
+	// The gateway serves requests sent to it as a proxy, so a reply for an unresolvable host proves the request went through it.
+	proxy := startGateway(t, newKeyEchoGateway())
+	client, err := sdk.New("mykey", sdk.WithBaseUrl("http://gateway.invalid"), sdk.WithProxyUrl(proxy))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+
+	res, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"})
+	if err != nil || res.Response.Content != "mykey" {
+		t.Fatalf("Expected the request to go through the proxy, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+
+	for _, proxyUrl := range []string{"proxy.internal:3128", "ftp://proxy.internal"} {
+		if _, err := sdk.New("mykey", sdk.WithProxyUrl(proxyUrl)); err == nil {
+			t.Fatalf("Expected the invalid proxy URL %q to be rejected", proxyUrl)
+		}
+	}
+	if _, err := sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", ProxyUrl: proxy, HttpClient: http.DefaultClient}); err == nil {
+		t.Fatalf("Expected a proxy URL to be rejected along with a custom HTTP client")
+	}
+}
+
+func TestTlsConfig(t *testing.T) {
+	baseUrl, certFile, _ := startTlsGateway(t, newKeyEchoGateway(), false)
+	request := &apigatewayv1.ChatCompleteRequest{Model: "model"}
+
+	// The gateway's self-signed certificate is not trusted by default.
+	client, err := sdk.New("mykey", sdk.WithBaseUrl(baseUrl))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	if _, err := client.ChatComplete(context.Background(), request); err == nil {
+		t.Fatalf("Expected the untrusted certificate to be rejected")
+	}
+
+	config, err := sdk.TlsConfigFromFiles(certFile, "", "")
+	if err != nil {
+		t.Fatalf("TlsConfigFromFiles failed with error %v", err)
+	}
+	client, err = sdk.New("mykey", sdk.WithBaseUrl(baseUrl), sdk.WithTlsConfig(config))
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	if res, err := client.ChatComplete(context.Background(), request); err != nil || res.Response.Content != "mykey" {
+		t.Fatalf("Expected the custom CA to be trusted, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+}
+
+func TestTlsConfigMutual(t *testing.T) {
+	baseUrl, certFile, keyFile := startTlsGateway(t, newKeyEchoGateway(), true)
+	request := &apigatewayv1.ChatCompleteRequest{Model: "model"}
+
+	withoutCert, err := sdk.TlsConfigFromFiles(certFile, "", "")
+	if err != nil {
+		t.Fatalf("TlsConfigFromFiles failed with error %v", err)
+	}
+	client, err := sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", BaseUrl: baseUrl, TlsConfig: withoutCert})
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	if _, err := client.ChatComplete(context.Background(), request); err == nil {
+		t.Fatalf("Expected the gateway to require a client certificate")
+	}
+
+	withCert, err := sdk.TlsConfigFromFiles(certFile, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("TlsConfigFromFiles failed with error %v", err)
+	}
+	client, err = sdk.NewClient(sdk.ClientOptions{ApiKey: "mykey", BaseUrl: baseUrl, TlsConfig: withCert})
+	if err != nil {
+		t.Fatalf("Client creation failed with error %v", err)
+	}
+	if res, err := client.ChatComplete(context.Background(), request); err != nil || res.Response.Content != "mykey" {
+		t.Fatalf("Expected the client certificate to be accepted, got %q (error %v)", res.GetResponse().GetContent(), err)
+	}
+
+	if _, err := sdk.TlsConfigFromFiles("", certFile, ""); err == nil {
+		t.Fatalf("Expected a client certificate without a key to be rejected")
+	}
+}
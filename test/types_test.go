@@ -0,0 +1,121 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestComplete(t *testing.T) {
+	gateway := newChatGateway()
+	var received *apigatewayv1.ChatCompleteRequest
+	chatComplete := gateway.chatComplete
+	gateway.chatComplete = func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+		received = req.Msg
+		return chatComplete(ctx, req)
+	}
+	client := newTestClient(t, gateway, sdk.ClientOptions{})
+
+	res, err := client.Complete(context.Background(), &sdk.ChatRequest{
+		Model:    "model",
+		Messages: []sdk.Message{{Role: sdk.RoleSystem, Content: "Be brief"}, {Role: sdk.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed with error %v", err)
+	}
+	if res.Message != (sdk.Message{Role: sdk.RoleAssistant, Content: "Hi there"}) || res.Usage.TotalTokens != 2 {
+		t.Fatalf("Unexpected response %+v", res)
+	}
+	if received.Model != "model" || len(received.Message) != 2 || received.Message[0].Role != "system" || received.Message[1].Content != "Hello" {
+		t.Fatalf("Unexpected request %v", received)
+	}
+
+	stream, err := client.CompleteStream(context.Background(), &sdk.ChatRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("CompleteStream failed with error %v", err)
+	}
+	tokens, err := stream.TokenStream.ReadAll()
+	if err != nil || !slices.Equal(tokens, []string{"Hi", " there"}) {
+		t.Fatalf("Unexpected tokens %q and error %v", tokens, err)
+	}
+}
+
+func TestNativeTypes(t *testing.T) {
+	request := sdk.ChatRequestFromProto(&apigatewayv1.ChatCompleteRequest{
+		Model:   "model",
+		Message: []*apigatewayv1.ChatCompleteMessage{{Role: "user", Content: "Hello"}},
+	})
+	if request.Model != "model" || !slices.Equal(request.Messages, []sdk.Message{{Role: sdk.RoleUser, Content: "Hello"}}) {
+		t.Fatalf("Unexpected request %+v", request)
+	}
+
+	embedding := sdk.EmbedResponseFromProto(&apigatewayv1.EmbedResponse{
+		Model: "model",
+		Data: []*apigatewayv1.EmbedResponse_Data{
+			{Index: 1, Embedding: []float32{2}},
+			{Index: 0, Embedding: []float32{1}},
+		},
+		Usage: &apigatewayv1.EmbedResponse_Usage{PromptTokens: 3, TotalTokens: 3},
+	})
+	if len(embedding.Embeddings) != 2 || embedding.Embeddings[0][0] != 1 || embedding.Embeddings[1][0] != 2 || embedding.Usage.PromptTokens != 3 {
+		t.Fatalf("Expected embeddings in the order of their index, got %+v", embedding)
+	}
+
+	image := (&sdk.ImageRequest{Model: "model", Prompt: "a cat", Count: 2, Quality: sdk.ImageQualityHd}).Proto()
+	if image.Count != 2 || image.Quality != apigatewayv1.ImageQuality_IMAGE_QUALITY_HD {
+		t.Fatalf("Unexpected image request %v", image)
+	}
+	images := sdk.ImageResponseFromProto(&apigatewayv1.TextToImageResponse{
+		Images: []*apigatewayv1.TextToImageResponse_Image{{Url: "https://example.com/cat.png", ExpiresTs: 1700000000}},
+	})
+	if len(images.Images) != 1 || !images.Images[0].ExpiresAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("Unexpected images %+v", images)
+	}
+
+	transcription := sdk.TranscribeResponseFromProto(&apigatewayv1.TranscribeResponse{
+		Text:  "hello",
+		Words: []*apigatewayv1.TranscribeResponse_Word{{Word: "hello", StartSecond: 0.5, EndSecond: 1.25}},
+	})
+	if len(transcription.Words) != 1 || transcription.Words[0] != (sdk.Word{Text: "hello", Start: 500 * time.Millisecond, End: 1250 * time.Millisecond}) {
+		t.Fatalf("Unexpected transcription %+v", transcription)
+	}
+}
+
+func TestNativeNilRequest(t *testing.T) {
+	client := newTestClient(t, newChatGateway(), sdk.ClientOptions{})
+
+	if _, err := client.Complete(context.Background(), nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError from Complete, got %v", err)
+	}
+	if _, err := client.CompleteStream(context.Background(), nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError from CompleteStream, got %v", err)
+	}
+	if _, err := client.CreateEmbedding(context.Background(), nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError from CreateEmbedding, got %v", err)
+	}
+	if _, err := client.GenerateImage(context.Background(), nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError from GenerateImage, got %v", err)
+	}
+	if _, err := client.TranscribeAudio(context.Background(), nil); !errors.Is(err, sdk.NilRequestError) {
+		t.Fatalf("Expected NilRequestError from TranscribeAudio, got %v", err)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	messages := sdk.Messages(sdk.SystemMessage("Be brief"), sdk.UserMessage("Hello"), sdk.AssistantMessage("Hi"))
+
+	expected := []string{"system: Be brief", "user: Hello", "assistant: Hi"}
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(messages))
+	}
+	for i, message := range messages {
+		if actual := message.Role + ": " + message.Content; actual != expected[i] {
+			t.Fatalf("Expected message %q, got %q", expected[i], actual)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// usageRecord is a single call of the OnUsage callback.
+type usageRecord struct {
+	method string
+	model  string
+	usage  sdk.TokenUsage
+}
+
+// recordUsage returns client options which record every OnUsage call into records.
+func recordUsage(records *[]usageRecord) sdk.ClientOptions {
+	return sdk.ClientOptions{
+		OnUsage: func(method string, model string, usage sdk.TokenUsage) {
+			*records = append(*records, usageRecord{method, model, usage})
+		},
+	}
+}
+
+// drainStream reads a token stream to its end.
+func drainStream(t *testing.T, res *sdk.ChatCompleteStreamResponse) error {
+	t.Helper()
+	for {
+		if _, err := res.TokenStream.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func TestOnUsageChatComplete(t *testing.T) {
+	var records []usageRecord
+	client := newTestClient(t, newChatGateway(), recordUsage(&records))
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err != nil {
+		t.Fatalf("ChatComplete failed with error %v", err)
+	}
+
+	expected := []usageRecord{{"ChatComplete", "model", sdk.TokenUsage{CompletionTokens: 2, TotalTokens: 2}}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Expected usage %+v, got %+v", expected, records)
+	}
+}
+
+func TestOnUsageEmbed(t *testing.T) {
+	var records []usageRecord
+	client := newTestClient(t, newEmbedGateway(), recordUsage(&records))
+
+	if _, err := client.EmbedBatch(context.Background(), "model", []string{"a", "b"}); err != nil {
+		t.Fatalf("EmbedBatch failed with error %v", err)
+	}
+
+	usage := sdk.TokenUsage{PromptTokens: 1, TotalTokens: 1}
+	expected := []usageRecord{{"Embed", "model", usage}, {"Embed", "model", usage}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Expected usage %+v, got %+v", expected, records)
+	}
+}
+
+func TestOnUsageChatCompleteStream(t *testing.T) {
+	var records []usageRecord
+	client := newTestClient(t, newStreamGateway("Hello", ", ", "world"), recordUsage(&records))
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("Expected no usage before the stream ends, got %+v", records)
+	}
+	if err := drainStream(t, res); err != nil {
+		t.Fatalf("Read failed with error %v", err)
+	}
+	res.TokenStream.Read()
+
+	// Timings vary, so only their presence is checked.
+	for i, record := range records {
+		if record.usage.FirstTokenLatency <= 0 || record.usage.TokensPerSecond <= 0 {
+			t.Fatalf("Expected stream timings to be reported, got %+v", record.usage)
+		}
+		records[i].usage.FirstTokenLatency, records[i].usage.TokensPerSecond = 0, 0
+	}
+
+	expected := []usageRecord{{"ChatCompleteStream", "model", sdk.TokenUsage{CompletionTokens: 3, TotalTokens: 3}}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Expected usage %+v, got %+v", expected, records)
+	}
+}
+
+func TestOnUsageNotCalledOnFailure(t *testing.T) {
+	gateway := &fakeGateway{
+		chatComplete: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteRequest]) (*connect.Response[apigatewayv1.ChatCompleteResponse], error) {
+			return nil, connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+		},
+		chatCompleteStream: func(ctx context.Context, req *connect.Request[apigatewayv1.ChatCompleteStreamRequest], stream *connect.ServerStream[apigatewayv1.ChatCompleteStreamResponse]) error {
+			if err := sendChunks(stream, "assistant", "", "Hello"); err != nil {
+				return err
+			}
+			return connect.NewError(connect.CodeInternal, errors.New("gateway crashed"))
+		},
+	}
+	var records []usageRecord
+	client := newTestClient(t, gateway, recordUsage(&records))
+
+	if _, err := client.ChatComplete(context.Background(), &apigatewayv1.ChatCompleteRequest{Model: "model"}); err == nil {
+		t.Fatalf("Expected ChatComplete to fail")
+	}
+
+	res, err := client.ChatCompleteStream(context.Background(), &apigatewayv1.ChatCompleteStreamRequest{Model: "model"})
+	if err != nil {
+		t.Fatalf("ChatCompleteStream failed with error %v", err)
+	}
+	if err := drainStream(t, res); err == nil {
+		t.Fatalf("Expected the stream to fail")
+	}
+
+	if len(records) != 0 {
+		t.Fatalf("Expected no usage for failed calls, got %+v", records)
+	}
+}
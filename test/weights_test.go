@@ -0,0 +1,71 @@
+package test
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"context"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"math"
+	"testing"
+)
+
+func TestModelWeights(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{
+		ModelWeights: map[string]map[string]float64{
+			"model": {"model": 3, "canary": 1},
+		},
+	})
+
+	const calls = 2000
+	counts := map[string]int{}
+	for i := 0; i < calls; i++ {
+		var metadata sdk.CallMetadata
+		ctx := sdk.WithCallMetadata(context.Background(), &metadata)
+		if _, err := client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: "model", Input: "text"}); err != nil {
+			t.Fatalf("Embed failed with error %v", err)
+		}
+		counts[metadata.Model]++
+	}
+
+	// The canary share is expected to be 25%, and is well within 5 percentage points of it with overwhelming probability.
+	share := float64(counts["canary"]) / calls
+	if len(counts) != 2 || math.Abs(share-0.25) > 0.05 {
+		t.Fatalf("Expected about 25%% of calls to go to the canary, got %v", counts)
+	}
+}
+
+func TestModelWeightsUnlistedModel(t *testing.T) {
+	client := newTestClient(t, newEmbedGateway(), sdk.ClientOptions{
+		ModelWeights: map[string]map[string]float64{
+			"model": {"canary": 1},
+		},
+	})
+
+	var metadata sdk.CallMetadata
+	ctx := sdk.WithCallMetadata(context.Background(), &metadata)
+	if _, err := client.Embed(ctx, &apigatewayv1.EmbedRequest{Model: "other", Input: "text"}); err != nil {
+		t.Fatalf("Embed failed with error %v", err)
+	}
+	if metadata.Model != "other" {
+		t.Fatalf("Expected an unlisted model to be used as-is, got %q", metadata.Model)
+	}
+}
+
+func TestModelWeightsValidation(t *testing.T) {
+	invalid := []map[string]float64{
+		{},
+		{"a": 0},
+		{"a": -1, "b": 2},
+		{"a": math.Inf(1)},
+		{"a": math.NaN()},
+	}
+
+	for _, weights := range invalid {
+		_, err := sdk.NewClient(sdk.ClientOptions{
+			ApiKey:       "mykey",
+			ModelWeights: map[string]map[string]float64{"model": weights},
+		})
+		if err == nil {
+			t.Errorf("Expected weights %v to be rejected", weights)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package function_go_sdk
+
+import (
+	"connectrpc.com/connect"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// StreamIdleTimeoutError is the underlying error of a stream which received no chunk within Timeouts.StreamIdle.
+// It is wrapped in a *connect.Error with connect.CodeDeadlineExceeded.
+var StreamIdleTimeoutError = errors.New("no stream chunk was received within the idle timeout")
+
+// Timeouts are timeouts applied automatically to calls, in addition to ClientOptions.Timeout, which is the default timeout of unary calls.
+// Zero values mean that the corresponding timeout is disabled.
+type Timeouts struct {
+	// Dial is the maximum duration of establishing a connection to a gateway.
+	// It only applies if ClientOptions.HttpClient is unspecified, as the connections of a custom HTTP client are its own.
+	Dial time.Duration
+
+	// StreamIdle is the maximum duration to wait for each chunk of a stream, including the first one.
+	// Unlike a total deadline, it lets long responses stream for as long as they keep making progress,
+	// while still catching streams that stall. A stalled stream fails with connect.CodeDeadlineExceeded, wrapping StreamIdleTimeoutError.
+	// Time spent by the caller between reads does not count towards it.
+	StreamIdle time.Duration
+
+	// Methods are timeouts of unary calls of specific methods, which take precedence over ClientOptions.Timeout.
+	// They are keyed by method name: "ChatComplete", "Embed", "TextToImage" or "Transcribe"; other keys make NewClient fail.
+	// A zero or negative timeout disables the default timeout for the method.
+	Methods map[string]time.Duration
+}
+
+// Names of the unary methods of the API gateway, which are valid keys of Timeouts.Methods.
+var unaryMethods = []string{"ChatComplete", "Embed", "TextToImage", "Transcribe"}
+
+// Validates the per-method timeouts.
+func validateMethodTimeouts(methods map[string]time.Duration) error {
+	for method := range methods {
+		if !slices.Contains(unaryMethods, method) {
+			return fmt.Errorf("unknown method %q in per-method timeouts, expected one of %v", method, unaryMethods)
+		}
+	}
+	return nil
+}
+
+// timeoutInterceptor applies a deadline to unary requests, unless it is overridden by WithCallTimeout,
+// and an idle timeout to streams, between chunks.
+type timeoutInterceptor struct {
+	// The default timeout of unary requests.
+	timeout time.Duration
+
+	// Timeouts of unary requests, keyed by method name, taking precedence over timeout.
+	methodTimeouts map[string]time.Duration
+
+	// The maximum duration to wait for each chunk of a stream.
+	streamIdle time.Duration
+}
+
+func (i *timeoutInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		timeout := i.timeout
+		if methodTimeout, ok := i.methodTimeouts[path.Base(req.Spec().Procedure)]; ok {
+			timeout = methodTimeout
+		}
+		if options := callOptionsFrom(ctx); options != nil && options.hasTimeout {
+			timeout = options.timeout
+		}
+		if timeout <= 0 {
+			return next(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return next(ctx, req)
+	}
+}
+
+func (i *timeoutInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		if i.streamIdle <= 0 {
+			return next(ctx, spec)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		return &idleTimeoutConn{
+			StreamingClientConn: next(ctx, spec),
+			timeout:             i.streamIdle,
+			cancel:              cancel,
+		}
+	}
+}
+
+func (i *timeoutInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// idleTimeoutConn cancels a stream if receiving a message takes longer than the timeout.
+type idleTimeoutConn struct {
+	connect.StreamingClientConn
+
+	timeout time.Duration
+	cancel  context.CancelFunc
+
+	// Whether the stream was canceled because of the timeout.
+	timedOut atomic.Bool
+}
+
+func (c *idleTimeoutConn) Receive(msg any) error {
+	timer := time.AfterFunc(c.timeout, func() {
+		c.timedOut.Store(true)
+		c.cancel()
+	})
+	err := c.StreamingClientConn.Receive(msg)
+	timer.Stop()
+
+	if err != nil && c.timedOut.Load() {
+		return connect.NewError(connect.CodeDeadlineExceeded, StreamIdleTimeoutError)
+	}
+	return err
+}
+
+func (c *idleTimeoutConn) CloseResponse() error {
+	defer c.cancel()
+	return c.StreamingClientConn.CloseResponse()
+}
@@ -0,0 +1,32 @@
+package tokens_test
+
+import (
+	"fmt"
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"github.com/fxnlabs/function-go-sdk/tokens"
+	"strings"
+)
+
+func ExampleCountTokens() {
+	messages := []sdk.Message{
+		sdk.SystemMessage("Answer in French."),
+		sdk.UserMessage("What is the capital of Italy?"),
+	}
+	fmt.Println(tokens.CountTokens("some-model", messages))
+	// Output:
+	// 21
+}
+
+func ExampleRegister() {
+	// A tokenizer which counts words, standing in for the model's own tokenizer.
+	tokens.Register("word-model", tokens.TokenizerFunc(func(text string) int {
+		return len(strings.Fields(text))
+	}))
+	defer tokens.Register("word-model", nil)
+
+	fmt.Println(tokens.Count("word-model", "What is the capital of Italy?"))
+	fmt.Println(tokens.Count("some-model", "What is the capital of Italy?"))
+	// Output:
+	// 6
+	// 8
+}
@@ -0,0 +1,73 @@
+// Package tokens counts the tokens of chat messages, so that prompts can be budgeted and their cost estimated before they are sent,
+// rather than finding out about context limits from a failed request.
+//
+// Models on the Function Network use a variety of tokenizers, and the gateway does not count tokens, so counts are estimates
+// unless a tokenizer is registered for the model with Register, such as one wrapping the model's own tokenizer library.
+package tokens
+
+import (
+	sdk "github.com/fxnlabs/function-go-sdk"
+	"sync"
+)
+
+// MessageOverhead is the number of tokens counted per message in addition to its content, for its role and the delimiters
+// of the model's chat template. It varies between models, and this is a typical value.
+const MessageOverhead = 4
+
+// Tokenizer counts the tokens in a piece of text.
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// TokenizerFunc is a function which implements Tokenizer.
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) Count(text string) int {
+	return f(text)
+}
+
+// Estimate is the tokenizer of models without a registered tokenizer. It is sdk.EstimateTokens, which estimates one token
+// per four characters, rounded up, as is typical of English text, so that counts agree with those of the SDK, such as for ChatSession.
+var Estimate Tokenizer = TokenizerFunc(sdk.EstimateTokens)
+
+var (
+	mu         sync.RWMutex
+	tokenizers = map[string]Tokenizer{}
+)
+
+// Register sets the tokenizer of a model, replacing any previous one. A nil tokenizer reverts the model to Estimate.
+// It is safe to call concurrently with counting.
+func Register(model string, tokenizer Tokenizer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tokenizer == nil {
+		delete(tokenizers, model)
+	} else {
+		tokenizers[model] = tokenizer
+	}
+}
+
+// For returns the tokenizer of a model, or Estimate if none is registered.
+func For(model string) Tokenizer {
+	mu.RLock()
+	defer mu.RUnlock()
+	if tokenizer, ok := tokenizers[model]; ok {
+		return tokenizer
+	}
+	return Estimate
+}
+
+// Count returns the number of tokens in text, for the given model.
+func Count(model string, text string) int {
+	return For(model).Count(text)
+}
+
+// CountTokens returns the number of tokens of messages, for the given model, including MessageOverhead for each message.
+func CountTokens(model string, messages []sdk.Message) int {
+	tokenizer := For(model)
+	tokens := 0
+	for _, message := range messages {
+		tokens += tokenizer.Count(message.Content) + MessageOverhead
+	}
+	return tokens
+}
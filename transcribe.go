@@ -0,0 +1,43 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"strings"
+	"time"
+)
+
+// FormatParagraphs turns a transcription into readable text by starting a new paragraph wherever the pause between two words
+// exceeds pauseThreshold. Words within a paragraph are separated by single spaces, and paragraphs by a blank line.
+//
+// If the response has no word timestamps, its text is returned unchanged.
+func FormatParagraphs(resp *apigatewayv1.TranscribeResponse, pauseThreshold time.Duration) string {
+	if !hasWordTimestamps(resp) {
+		return resp.GetText()
+	}
+
+	var text strings.Builder
+	for i, word := range resp.Words {
+		if i > 0 {
+			pause := time.Duration((word.StartSecond - resp.Words[i-1].EndSecond) * float64(time.Second))
+			if pause > pauseThreshold {
+				text.WriteString("\n\n")
+			} else {
+				text.WriteString(" ")
+			}
+		}
+		text.WriteString(strings.TrimSpace(word.Word))
+	}
+
+	return text.String()
+}
+
+// Returns whether the response has words with timestamps.
+// Some models return words without timing information, in which case every timestamp is zero.
+func hasWordTimestamps(resp *apigatewayv1.TranscribeResponse) bool {
+	for _, word := range resp.GetWords() {
+		if word.EndSecond > 0 {
+			return true
+		}
+	}
+	return false
+}
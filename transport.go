@@ -0,0 +1,97 @@
+package function_go_sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Returns the HTTP client configured by the options: either the custom one, or one built from the transport options,
+// which behaves like http.DefaultClient otherwise.
+func newHttpClient(options ClientOptions) (HttpClient, error) {
+	if options.HttpClient != nil {
+		if options.ProxyUrl != "" || options.TlsConfig != nil {
+			return nil, fmt.Errorf("ProxyUrl and TlsConfig cannot be combined with a custom HttpClient, whose transport must be configured instead")
+		}
+		return options.HttpClient, nil
+	}
+	if options.Timeouts.Dial <= 0 && options.ProxyUrl == "" && options.TlsConfig == nil {
+		return http.DefaultClient, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if options.Timeouts.Dial > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: options.Timeouts.Dial, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if options.ProxyUrl != "" {
+		proxy, err := parseProxyUrl(options.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", options.ProxyUrl, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if options.TlsConfig != nil {
+		transport.TLSClientConfig = options.TlsConfig.Clone()
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// Parses a proxy URL, which must be absolute, with a scheme supported by http.Transport.
+func parseProxyUrl(proxyUrl string) (*url.URL, error) {
+	parsed, err := url.Parse(proxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("proxy URL must be absolute")
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", parsed.Scheme)
+	}
+}
+
+// TlsConfigFromFiles creates a TLS configuration from PEM files, for use as ClientOptions.TlsConfig.
+// Each file is optional, and may be left empty:
+//   - caFile holds CA certificates to trust in addition to the system ones, such as the CA of a corporate proxy which inspects TLS traffic.
+//   - certFile and keyFile hold a client certificate and its private key, for gateways which require mutual TLS.
+//     They must be specified together.
+func TlsConfigFromFiles(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificate found in CA file %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client certificate and key files must be specified together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,339 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// The types in this file are SDK-native equivalents of the generated apigatewayv1 messages, for use with Complete, CompleteStream,
+// CreateEmbedding, GenerateImage and TranscribeAudio, so that callers do not need to depend on the generated packages.
+// Each converts to and from its protobuf message, for use with the protobuf-based methods, such as ChatComplete.
+
+// Role is the role of the sender of a chat message.
+type Role string
+
+const (
+	// RoleSystem is for instructions that steer the model's behavior, usually as the first message.
+	RoleSystem Role = "system"
+
+	// RoleUser is for messages written by the user.
+	RoleUser Role = "user"
+
+	// RoleAssistant is for messages generated by the model.
+	RoleAssistant Role = "assistant"
+)
+
+// Message is a chat message.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Proto converts the message to its protobuf message.
+func (m Message) Proto() *apigatewayv1.ChatCompleteMessage {
+	return &apigatewayv1.ChatCompleteMessage{Role: string(m.Role), Content: m.Content}
+}
+
+// MessageFromProto converts a protobuf message to a Message. A nil message converts to the zero Message.
+func MessageFromProto(message *apigatewayv1.ChatCompleteMessage) Message {
+	return Message{Role: Role(message.GetRole()), Content: message.GetContent()}
+}
+
+// SystemMessage returns a system message with the given content.
+func SystemMessage(content string) Message {
+	return Message{Role: RoleSystem, Content: content}
+}
+
+// UserMessage returns a user message with the given content.
+func UserMessage(content string) Message {
+	return Message{Role: RoleUser, Content: content}
+}
+
+// AssistantMessage returns an assistant message with the given content.
+func AssistantMessage(content string) Message {
+	return Message{Role: RoleAssistant, Content: content}
+}
+
+// Messages converts messages, in order, to protobuf messages, to build the chat history of a ChatComplete or ChatCompleteStream request:
+//
+//	request := &apigatewayv1.ChatCompleteRequest{
+//		Model:   model,
+//		Message: sdk.Messages(sdk.SystemMessage("Answer in French."), sdk.UserMessage("Hello!")),
+//	}
+func Messages(messages ...Message) []*apigatewayv1.ChatCompleteMessage {
+	converted := make([]*apigatewayv1.ChatCompleteMessage, len(messages))
+	for i, message := range messages {
+		converted[i] = message.Proto()
+	}
+	return converted
+}
+
+// ChatRequest is a request for a chat completion, for Complete and CompleteStream.
+type ChatRequest struct {
+	// Model is the model to complete the chat with.
+	Model string
+
+	// Messages is the conversation to complete, in order.
+	Messages []Message
+}
+
+// Proto converts the request to a protobuf request for ChatComplete.
+func (r *ChatRequest) Proto() *apigatewayv1.ChatCompleteRequest {
+	return &apigatewayv1.ChatCompleteRequest{Model: r.Model, Message: Messages(r.Messages...)}
+}
+
+// StreamProto converts the request to a protobuf request for ChatCompleteStream.
+func (r *ChatRequest) StreamProto() *apigatewayv1.ChatCompleteStreamRequest {
+	return &apigatewayv1.ChatCompleteStreamRequest{Model: r.Model, Message: Messages(r.Messages...)}
+}
+
+// ChatRequestFromProto converts a protobuf ChatComplete request to a ChatRequest.
+func ChatRequestFromProto(request *apigatewayv1.ChatCompleteRequest) *ChatRequest {
+	messages := make([]Message, len(request.GetMessage()))
+	for i, message := range request.GetMessage() {
+		messages[i] = MessageFromProto(message)
+	}
+	return &ChatRequest{Model: request.GetModel(), Messages: messages}
+}
+
+// ChatResponse is the response to a ChatRequest.
+type ChatResponse struct {
+	// Message is the generated message.
+	Message Message
+
+	// Usage is the token usage of the request, as reported by the gateway.
+	Usage TokenUsage
+}
+
+// ChatResponseFromProto converts a protobuf ChatComplete response to a ChatResponse.
+func ChatResponseFromProto(res *apigatewayv1.ChatCompleteResponse) *ChatResponse {
+	usage, _ := responseUsage(res)
+	return &ChatResponse{Message: MessageFromProto(res.GetResponse()), Usage: usage}
+}
+
+// EmbedRequest is a request for an embedding, for CreateEmbedding.
+type EmbedRequest struct {
+	// Model is the embedding model.
+	Model string
+
+	// Input is the text to embed.
+	Input string
+}
+
+// Proto converts the request to a protobuf request for Embed.
+func (r *EmbedRequest) Proto() *apigatewayv1.EmbedRequest {
+	return &apigatewayv1.EmbedRequest{Model: r.Model, Input: r.Input}
+}
+
+// EmbedResponse is the response to an EmbedRequest.
+type EmbedResponse struct {
+	// Model is the model which generated the embeddings.
+	Model string
+
+	// Embeddings are the generated embeddings, in the order of their index.
+	Embeddings [][]float32
+
+	// Usage is the token usage of the request, as reported by the gateway.
+	Usage TokenUsage
+}
+
+// EmbedResponseFromProto converts a protobuf Embed response to an EmbedResponse.
+func EmbedResponseFromProto(res *apigatewayv1.EmbedResponse) *EmbedResponse {
+	data := slices.SortedStableFunc(slices.Values(res.GetData()), func(a, b *apigatewayv1.EmbedResponse_Data) int {
+		return cmp.Compare(a.GetIndex(), b.GetIndex())
+	})
+	embeddings := make([][]float32, len(data))
+	for i, d := range data {
+		embeddings[i] = d.GetEmbedding()
+	}
+
+	usage, _ := responseUsage(res)
+	return &EmbedResponse{Model: res.GetModel(), Embeddings: embeddings, Usage: usage}
+}
+
+// ImageQuality is the quality of generated images.
+type ImageQuality string
+
+const (
+	// ImageQualityStandard is the standard quality, which is faster and cheaper to generate.
+	ImageQualityStandard ImageQuality = "standard"
+
+	// ImageQualityHd is the high quality, with finer details.
+	ImageQualityHd ImageQuality = "hd"
+)
+
+// Converts the quality to its protobuf enum. Unknown qualities are unspecified, and left to the model's default.
+func (q ImageQuality) proto() apigatewayv1.ImageQuality {
+	switch q {
+	case ImageQualityStandard:
+		return apigatewayv1.ImageQuality_IMAGE_QUALITY_STANDARD
+	case ImageQualityHd:
+		return apigatewayv1.ImageQuality_IMAGE_QUALITY_HD
+	default:
+		return apigatewayv1.ImageQuality_IMAGE_QUALITY_UNSPECIFIED
+	}
+}
+
+// ImageRequest is a request to generate images, for GenerateImage.
+type ImageRequest struct {
+	// Model is the image generation model.
+	Model string
+
+	// Prompt describes the images to generate.
+	Prompt string
+
+	// Count is the number of images to generate. If unspecified, one image is generated.
+	Count int
+
+	// Quality is the quality of the images. If unspecified, the model's default is used.
+	Quality ImageQuality
+
+	// Size is the size of the images, as "<width>x<height>", such as "1024x1024". If unspecified, it defaults to "1024x1024".
+	Size string
+}
+
+// Proto converts the request to a protobuf request for TextToImage.
+func (r *ImageRequest) Proto() *apigatewayv1.TextToImageRequest {
+	return &apigatewayv1.TextToImageRequest{
+		Model:   r.Model,
+		Prompt:  r.Prompt,
+		Count:   uint32(max(r.Count, 0)),
+		Quality: r.Quality.proto(),
+		Size:    r.Size,
+	}
+}
+
+// Image is a generated image.
+type Image struct {
+	// Url is where the image can be downloaded, such as with Client.FetchImage, until ExpiresAt.
+	Url string
+
+	// ExpiresAt is when Url expires, or the zero time if the gateway did not say.
+	ExpiresAt time.Time
+}
+
+// ImageResponse is the response to an ImageRequest.
+type ImageResponse struct {
+	Images []Image
+}
+
+// ImageResponseFromProto converts a protobuf TextToImage response to an ImageResponse.
+func ImageResponseFromProto(res *apigatewayv1.TextToImageResponse) *ImageResponse {
+	images := make([]Image, len(res.GetImages()))
+	for i, image := range res.GetImages() {
+		images[i] = Image{Url: image.GetUrl()}
+		if image.GetExpiresTs() > 0 {
+			images[i].ExpiresAt = time.Unix(image.GetExpiresTs(), 0)
+		}
+	}
+	return &ImageResponse{Images: images}
+}
+
+// TranscribeRequest is a request to transcribe audio, for TranscribeAudio.
+type TranscribeRequest struct {
+	// Model is the transcription model.
+	Model string
+
+	// Url is where the audio file can be downloaded. It must end with the file extension of the audio format, such as ".mp3".
+	Url string
+}
+
+// Proto converts the request to a protobuf request for Transcribe.
+func (r *TranscribeRequest) Proto() *apigatewayv1.TranscribeRequest {
+	return &apigatewayv1.TranscribeRequest{Model: r.Model, Url: r.Url}
+}
+
+// Word is a transcribed word, with its timing in the audio.
+type Word struct {
+	Text string
+
+	// Start and End are the offsets from the start of the audio at which the word starts and ends.
+	Start time.Duration
+	End   time.Duration
+}
+
+// TranscribeResponse is the response to a TranscribeRequest.
+type TranscribeResponse struct {
+	// Text is the complete transcription.
+	Text string
+
+	// Words are the transcribed words, in order.
+	Words []Word
+}
+
+// TranscribeResponseFromProto converts a protobuf Transcribe response to a TranscribeResponse.
+func TranscribeResponseFromProto(res *apigatewayv1.TranscribeResponse) *TranscribeResponse {
+	words := make([]Word, len(res.GetWords()))
+	for i, word := range res.GetWords() {
+		words[i] = Word{
+			Text:  word.GetWord(),
+			Start: time.Duration(word.GetStartSecond() * float64(time.Second)),
+			End:   time.Duration(word.GetEndSecond() * float64(time.Second)),
+		}
+	}
+	return &TranscribeResponse{Text: res.GetText(), Words: words}
+}
+
+// Complete is like ChatComplete, with SDK-native types. A nil request fails with NilRequestError.
+func (c *Client) Complete(ctx context.Context, request *ChatRequest, opts ...CallOption) (*ChatResponse, error) {
+	if request == nil {
+		return nil, c.methodError("Complete", NilRequestError)
+	}
+
+	res, err := c.ChatComplete(ctx, request.Proto(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ChatResponseFromProto(res), nil
+}
+
+// CompleteStream is like ChatCompleteStream, with an SDK-native request. A nil request fails with NilRequestError.
+func (c *Client) CompleteStream(ctx context.Context, request *ChatRequest, opts ...CallOption) (*ChatCompleteStreamResponse, error) {
+	if request == nil {
+		return nil, c.methodError("CompleteStream", NilRequestError)
+	}
+
+	return c.ChatCompleteStream(ctx, request.StreamProto(), opts...)
+}
+
+// CreateEmbedding is like Embed, with SDK-native types. A nil request fails with NilRequestError.
+func (c *Client) CreateEmbedding(ctx context.Context, request *EmbedRequest, opts ...CallOption) (*EmbedResponse, error) {
+	if request == nil {
+		return nil, c.methodError("CreateEmbedding", NilRequestError)
+	}
+
+	res, err := c.Embed(ctx, request.Proto(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return EmbedResponseFromProto(res), nil
+}
+
+// GenerateImage is like TextToImage, with SDK-native types. A nil request fails with NilRequestError.
+func (c *Client) GenerateImage(ctx context.Context, request *ImageRequest, opts ...CallOption) (*ImageResponse, error) {
+	if request == nil {
+		return nil, c.methodError("GenerateImage", NilRequestError)
+	}
+
+	res, err := c.TextToImage(ctx, request.Proto(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ImageResponseFromProto(res), nil
+}
+
+// TranscribeAudio is like Transcribe, with SDK-native types. A nil request fails with NilRequestError.
+func (c *Client) TranscribeAudio(ctx context.Context, request *TranscribeRequest, opts ...CallOption) (*TranscribeResponse, error) {
+	if request == nil {
+		return nil, c.methodError("TranscribeAudio", NilRequestError)
+	}
+
+	res, err := c.Transcribe(ctx, request.Proto(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return TranscribeResponseFromProto(res), nil
+}
@@ -0,0 +1,59 @@
+package function_go_sdk
+
+import (
+	apigatewayv1 "buf.build/gen/go/fxnlabs/api-gateway/protocolbuffers/go/apigateway/v1"
+	"time"
+)
+
+// TokenUsage holds the token counts of a call, as reported to ClientOptions.OnUsage.
+// Counts and measurements that are not reported for a method are zero.
+type TokenUsage struct {
+	// PromptTokens is the number of tokens in the request.
+	// It is only reported by Embed.
+	PromptTokens int
+
+	// CompletionTokens is the number of tokens generated in the response.
+	// It is reported by ChatComplete, and by ChatCompleteStream, where it is the number of tokens read from the stream.
+	CompletionTokens int
+
+	// TotalTokens is the number of tokens billed for the call.
+	TotalTokens int
+
+	// FirstTokenLatency is the time to first token, as returned by ChatCompleteStreamResponse.FirstTokenLatency.
+	// It is only reported by ChatCompleteStream.
+	FirstTokenLatency time.Duration
+
+	// TokensPerSecond is the generation speed, as returned by ChatCompleteStreamResponse.TokensPerSecond.
+	// It is only reported by ChatCompleteStream.
+	TokensPerSecond float64
+}
+
+// Returns the token usage reported in a response message, and whether the message reports usage at all.
+func responseUsage(msg any) (TokenUsage, bool) {
+	switch res := msg.(type) {
+	case *apigatewayv1.ChatCompleteResponse:
+		return TokenUsage{
+			CompletionTokens: int(res.TokenCount),
+			TotalTokens:      int(res.TokenCount),
+		}, true
+	case *apigatewayv1.EmbedResponse:
+		if res.Usage == nil {
+			return TokenUsage{}, false
+		}
+		return TokenUsage{
+			PromptTokens: int(res.Usage.PromptTokens),
+			TotalTokens:  int(res.Usage.TotalTokens),
+		}, true
+	}
+	return TokenUsage{}, false
+}
+
+// Reports the usage of a successful unary call to the OnUsage callback, if the client has one and the response reports usage.
+func (c *Client) reportUsage(method string, model string, msg any) {
+	if c.onUsage == nil {
+		return
+	}
+	if usage, ok := responseUsage(msg); ok {
+		c.onUsage(method, model, usage)
+	}
+}
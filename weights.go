@@ -0,0 +1,84 @@
+package function_go_sdk
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// weightedModels is a set of models to pick from at random, each with a probability proportional to its weight.
+type weightedModels struct {
+	models []string
+
+	// Cumulative weights, in the same order as models. The last value is the total weight.
+	cumulative []float64
+}
+
+// Validates the weights and prepares them for selection.
+func newWeightedModels(weights map[string]float64) (*weightedModels, error) {
+	// Models are sorted so that selection does not depend on map iteration order.
+	models := make([]string, 0, len(weights))
+	for model := range weights {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+
+	w := &weightedModels{
+		models:     models,
+		cumulative: make([]float64, len(models)),
+	}
+	total := 0.0
+	for i, model := range models {
+		weight := weights[model]
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weight %v of model %q must be a finite, non-negative number", weight, model)
+		}
+
+		total += weight
+		w.cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("weights must add up to more than zero")
+	}
+
+	return w, nil
+}
+
+// Picks a model at random according to the weights.
+func (w *weightedModels) pick() string {
+	target := rand.Float64() * w.cumulative[len(w.cumulative)-1]
+	for i, cumulative := range w.cumulative {
+		if target < cumulative {
+			return w.models[i]
+		}
+	}
+	return w.models[len(w.models)-1]
+}
+
+// Validates model weights from client options, keyed by requested model.
+func newModelWeights(weights map[string]map[string]float64) (map[string]*weightedModels, error) {
+	modelWeights := make(map[string]*weightedModels, len(weights))
+	for requested, candidates := range weights {
+		w, err := newWeightedModels(candidates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid model weights for %q: %w", requested, err)
+		}
+		modelWeights[requested] = w
+	}
+	return modelWeights, nil
+}
+
+// Returns the request with its model replaced by a weighted random pick, if weights are configured for the requested model.
+// Otherwise, the request is returned as-is.
+func selectWeightedModel[T interface {
+	modelRequest
+	proto.Message
+}](c *Client, request T) T {
+	w, ok := c.modelWeights[request.GetModel()]
+	if !ok {
+		return request
+	}
+	return withModel(request, w.pick())
+}